@@ -0,0 +1,80 @@
+package request
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Attempts describes attempts made by Retry interceptor to get Response.
+type Attempts struct {
+	// Count is number of made attempts.
+	Count int
+
+	// Errors are errors of every attempt, nil for attempts got Response.
+	Errors []error
+
+	// StatusCodes are Response status codes of every attempt,
+	// zero for failed attempts.
+	StatusCodes []int
+
+	// Delay is total delay between attempts.
+	Delay time.Duration
+}
+
+type attemptsContextKey struct{}
+
+// attemptsRecorder collects attempts of single request
+// carried through request context.
+type attemptsRecorder struct {
+	mu       sync.Mutex
+	attempts Attempts
+}
+
+// withAttemptsContext sets attempts recorder to context.
+func withAttemptsContext(ctx context.Context, recorder *attemptsRecorder) context.Context {
+	return context.WithValue(ctx, attemptsContextKey{}, recorder)
+}
+
+// attemptsFromContext returns attempts recorder of request context if any.
+func attemptsFromContext(ctx context.Context) *attemptsRecorder {
+	recorder, _ := ctx.Value(attemptsContextKey{}).(*attemptsRecorder)
+
+	return recorder
+
+}
+
+// record adds attempt result with delay before the next attempt.
+func (r *attemptsRecorder) record(statusCode int, err error, delay time.Duration) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.attempts.Count++
+	r.attempts.Errors = append(r.attempts.Errors, err)
+	r.attempts.StatusCodes = append(r.attempts.StatusCodes, statusCode)
+	r.attempts.Delay += delay
+
+}
+
+// get returns copy of recorded attempts.
+func (r *attemptsRecorder) get() Attempts {
+	if r == nil {
+		return Attempts{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return Attempts{
+		Count:       r.attempts.Count,
+		Errors:      slices.Clone(r.attempts.Errors),
+		StatusCodes: slices.Clone(r.attempts.StatusCodes),
+		Delay:       r.attempts.Delay,
+	}
+
+}
@@ -0,0 +1,207 @@
+package request
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+var (
+	ErrUnsupportedFormBody = errors.New("unsupported form body")
+)
+
+const formTag = "form"
+
+// bodyEncoder lazily encodes request body,
+// returning encoded body and its content type.
+type bodyEncoder func() (io.Reader, string, error)
+
+// jsonBody encodes value as JSON.
+func jsonBody(value any) bodyEncoder {
+	return func() (io.Reader, string, error) {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return bytes.NewReader(data), ApplicationJSON, nil
+	}
+
+}
+
+// xmlBody encodes value as XML.
+func xmlBody(value any) bodyEncoder {
+	return func() (io.Reader, string, error) {
+		data, err := xml.Marshal(value)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return bytes.NewReader(data), ApplicationXML, nil
+	}
+
+}
+
+// formBody encodes value as application/x-www-form-urlencoded.
+func formBody(value any) bodyEncoder {
+	return func() (io.Reader, string, error) {
+		values, err := formValues(value)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return strings.NewReader(values.Encode()), ApplicationFormUrlencoded, nil
+	}
+
+}
+
+// formValues converts url.Values, map or struct with form tags to url.Values.
+// Struct field tag has form `form:"name,omitempty"`, "-" skips field,
+// untagged fields use field name.
+func formValues(value any) (url.Values, error) {
+	switch v := value.(type) {
+	case url.Values:
+		return v, nil
+	case map[string][]string:
+		return v, nil
+	case map[string]string:
+		values := make(url.Values, len(v))
+		for key, item := range v {
+			values.Set(key, item)
+		}
+
+		return values, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return url.Values{}, nil
+		}
+
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedFormBody, value)
+	}
+
+	values := make(url.Values)
+	if err := appendFormStruct(values, rv); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+
+}
+
+// appendFormStruct appends exported struct fields to values.
+// Embedded structs fields are promoted.
+func appendFormStruct(values url.Values, rv reflect.Value) error {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		tag := field.Tag.Get(formTag)
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		omitEmpty := options == "omitempty"
+
+		fieldValue := rv.Field(i)
+
+		if field.Anonymous && name == "" {
+			for fieldValue.Kind() == reflect.Pointer && !fieldValue.IsNil() {
+				fieldValue = fieldValue.Elem()
+			}
+
+			if fieldValue.Kind() == reflect.Struct {
+				if err := appendFormStruct(values, fieldValue); err != nil {
+					return err
+				}
+
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if omitEmpty && fieldValue.IsZero() {
+			continue
+		}
+
+		items, err := formFieldValues(fieldValue)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		values[name] = append(values[name], items...)
+	}
+
+	return nil
+
+}
+
+// formFieldValues formats field value, slices and arrays
+// produce value per element.
+func formFieldValues(rv reflect.Value) ([]string, error) {
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, nil
+		}
+
+		rv = rv.Elem()
+	}
+
+	if marshaler, ok := rv.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+
+		return []string{string(text)}, nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			return []string{string(rv.Bytes())}, nil
+		}
+
+		items := make([]string, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			item, err := formFieldValues(rv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, item...)
+		}
+
+		return items, nil
+	case reflect.Map, reflect.Struct, reflect.Func, reflect.Chan:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormBody, rv.Type())
+	default:
+		return []string{fmt.Sprint(rv.Interface())}, nil
+	}
+
+}
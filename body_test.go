@@ -0,0 +1,122 @@
+package request
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type testFormBase struct {
+	Tenant string `form:"tenant"`
+}
+
+type testForm struct {
+	testFormBase
+	Name     string    `form:"name"`
+	Age      int       `form:"age,omitempty"`
+	Tags     []string  `form:"tag"`
+	Enabled  *bool     `form:"enabled"`
+	Created  time.Time `form:"created,omitempty"`
+	Secret   string    `form:"-"`
+	Untagged float64
+	private  string
+}
+
+func TestFormValues(t *testing.T) {
+	enabled := true
+
+	type args struct {
+		value any
+	}
+
+	type want struct {
+		values url.Values
+		err    error
+	}
+
+	type test struct {
+		name string
+		args args
+		want want
+	}
+
+	tests := []test{
+		{
+			name: "url.Values",
+			args: args{
+				value: url.Values{"key": {"1", "2"}},
+			},
+			want: want{
+				values: url.Values{"key": {"1", "2"}},
+			},
+		},
+		{
+			name: "map[string]string",
+			args: args{
+				value: map[string]string{"key": "1"},
+			},
+			want: want{
+				values: url.Values{"key": {"1"}},
+			},
+		},
+		{
+			name: "Struct",
+			args: args{
+				value: &testForm{
+					testFormBase: testFormBase{Tenant: "acme"},
+					Name:         "John Doe",
+					Tags:         []string{"a", "b"},
+					Enabled:      &enabled,
+					Created:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+					Secret:       "secret",
+					Untagged:     1.5,
+					private:      "private",
+				},
+			},
+			want: want{
+				values: url.Values{
+					"tenant":   {"acme"},
+					"name":     {"John Doe"},
+					"tag":      {"a", "b"},
+					"enabled":  {"true"},
+					"created":  {"2024-01-02T03:04:05Z"},
+					"Untagged": {"1.5"},
+				},
+			},
+		},
+		{
+			name: "Unsupported value",
+			args: args{
+				value: 42,
+			},
+			want: want{
+				err: ErrUnsupportedFormBody,
+			},
+		},
+		{
+			name: "Unsupported field",
+			args: args{
+				value: struct {
+					Nested map[string]string `form:"nested"`
+				}{
+					Nested: map[string]string{},
+				},
+			},
+			want: want{
+				err: ErrUnsupportedFormBody,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			values, err := formValues(tc.args.value)
+
+			assert.Equal(t, tc.want.values, values)
+			assert.ErrorIs(t, err, tc.want.err)
+
+		})
+	}
+
+}
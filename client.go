@@ -1,8 +1,13 @@
 package request
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 )
 
@@ -17,50 +22,135 @@ const (
 
 type Client interface {
 	Request() Request
+
+	RegisterProtocol(
+		scheme string,
+		transport http.RoundTripper,
+	)
+
+	Template(
+		configure ...func(Request) Request,
+	) Template
 }
 
 type client struct {
 	httpClient                *http.Client
+	httpTransport             *http.Transport
+	transport                 http.RoundTripper
+	protocolTransport         *protocolTransport
+	interceptors              []Interceptor
 	timeout                   time.Duration
 	idleConnectionTimeout     time.Duration
 	maxIdleConnections        int
 	maxIdleConnectionsPerHost int
 	maxConnectionsPerHost     int
 	forceAttemptHTTP2         bool
+	tlsClientConfig           *tls.Config
+	rootCAs                   *x509.CertPool
+	minTLSVersion             uint16
+	serverName                string
+	cipherSuites              []uint16
+	certificatePins           []string
+	clientCertificate         *certificateReloader
+	proxyFunc                 func(*http.Request) (*url.URL, error)
+	noProxy                   []string
+	dialTimeout               time.Duration
+	keepAlive                 time.Duration
+	resolver                  *net.Resolver
+	resolve                   map[string][]string
+	localAddress              net.IP
+	localAddressErr           error
+	localInterface            string
+	unixSocket                string
+	baseURL                   *url.URL
+	baseURLErr                error
+	defaultHeader             http.Header
+	defaultQuery              url.Values
 }
 
 func NewClient(
 	options ...func(*client),
 ) Client {
-	httpClient := &http.Client{
-		Transport: http.DefaultTransport,
-	}
-	transport := httpClient.Transport.(*http.Transport)
-
 	client := &client{
-		httpClient:                httpClient,
 		timeout:                   DefaultTimeout,
 		idleConnectionTimeout:     DefaultIdleConnectionTimeout,
 		maxIdleConnections:        DefaultMaxIdleConnections,
 		maxConnectionsPerHost:     DefaultMaxConnectionsPerHost,
 		maxIdleConnectionsPerHost: DefaultMaxIdleConnectionsPerHost,
 		forceAttemptHTTP2:         DefaultForceAttemptHTTP2,
+		dialTimeout:               DefaultDialTimeout,
+		keepAlive:                 DefaultKeepAlive,
 	}
 
 	for _, option := range options {
 		option(client)
 	}
 
-	transport.MaxIdleConns = client.maxIdleConnectionsPerHost
-	transport.MaxConnsPerHost = client.maxIdleConnections
-	transport.MaxIdleConnsPerHost = client.maxIdleConnections
-	transport.IdleConnTimeout = client.idleConnectionTimeout
-	transport.ForceAttemptHTTP2 = client.forceAttemptHTTP2
+	if client.httpClient == nil {
+		client.httpClient = &http.Client{}
+	}
+
+	transport := client.transport
+	if transport == nil {
+		transport = client.httpClient.Transport
+	}
+
+	if transport == nil {
+		client.httpTransport = client.newTransport()
+		transport = newProxyTransport(client.httpTransport, client.proxy, client.lookupAddress)
+	}
+
+	client.protocolTransport = newProtocolTransport(transport)
+	client.protocolTransport.register(
+		unixScheme,
+		newUnixTransport(client.newTransport),
+	)
+
+	transport = client.protocolTransport
+
+	for i := range client.interceptors {
+		interceptor := client.interceptors[len(client.interceptors)-1-i]
+
+		transport = interceptor(transport)
+
+	}
+
+	client.httpClient.Transport = transport
 
 	return client
 
 }
 
+// newTransport builds dedicated http.Transport for the client,
+// so connection pool settings never leak into http.DefaultTransport
+// or into other clients.
+func (c *client) newTransport() *http.Transport {
+	var transport *http.Transport
+	if defaultTransport, ok := http.DefaultTransport.(*http.Transport); ok {
+		transport = defaultTransport.Clone()
+	} else {
+		transport = &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: time.Second,
+		}
+	}
+
+	transport.MaxIdleConns = c.maxIdleConnections
+	transport.MaxConnsPerHost = c.maxConnectionsPerHost
+	transport.MaxIdleConnsPerHost = c.maxIdleConnectionsPerHost
+	transport.IdleConnTimeout = c.idleConnectionTimeout
+	transport.ForceAttemptHTTP2 = c.forceAttemptHTTP2
+	transport.DialContext = c.dialContext
+
+	if config := c.tlsConfig(); config != nil {
+		transport.TLSClientConfig = config
+	}
+
+	return transport
+
+}
+
 func (c *client) Request() Request {
 	return &request{
 		client: c,
@@ -73,6 +163,7 @@ func (c *client) Request() Request {
 // WithTimeout sets timeout for all client requests.
 // Timeout implemented without using http.Client's Timeout property,
 // but with context. Client timeout has lesser priority than Request timeout property.
+// Timeout covers reading response body, context is released on body close.
 // If not provided, DefaultTimeout is used.
 func WithTimeout(timeout time.Duration) func(*client) {
 	return func(c *client) {
@@ -133,17 +224,65 @@ func WithForceAttemptHTTP2(forceAttemptHTTP2 bool) func(*client) {
 
 }
 
-// WithInterceptors wraps Client with given interceptors
-func WithInterceptors(interceptors ...Interceptor) func(*client) {
+// WithTransport sets custom http.RoundTripper used by the client.
+// Connection pool options are not applied to the given transport.
+// Interceptors still wrap it.
+func WithTransport(transport http.RoundTripper) func(*client) {
+	return func(c *client) {
+		c.transport = transport
+	}
+
+}
+
+// WithHTTPClient sets custom http.Client used by the client.
+// The given http.Client is copied, so it is never modified by interceptors.
+// If its Transport is nil, client builds own transport from options.
+func WithHTTPClient(httpClient *http.Client) func(*client) {
 	return func(c *client) {
-		for i := range interceptors {
-			interceptor := interceptors[len(interceptors)-1-i]
+		if httpClient == nil {
+			c.httpClient = nil
+			return
+		}
+
+		clone := *httpClient
+		c.httpClient = &clone
+	}
 
-			c.httpClient.Transport = interceptor(
-				c.httpClient.Transport,
-			)
+}
 
+// WithBaseURL sets URL relative request URLs are resolved against.
+// Base URL path is treated as directory, so "users" is resolved
+// against "https://host/v1" as "https://host/v1/users",
+// while "/users" is resolved as "https://host/users".
+func WithBaseURL(baseURL string) func(*client) {
+	return func(c *client) {
+		u, err := url.Parse(baseURL)
+		if err == nil && !u.IsAbs() {
+			err = fmt.Errorf("base URL %q is not absolute", baseURL)
 		}
+
+		if err != nil {
+			c.baseURL, c.baseURLErr = nil, err
+			return
+		}
+
+		if !strings.HasSuffix(u.Path, "/") {
+			u.Path += "/"
+			if u.RawPath != "" {
+				u.RawPath += "/"
+			}
+		}
+
+		c.baseURL, c.baseURLErr = u, nil
+	}
+
+}
+
+// WithInterceptors wraps Client with given interceptors.
+// The first interceptor is the outermost one.
+func WithInterceptors(interceptors ...Interceptor) func(*client) {
+	return func(c *client) {
+		c.interceptors = append(c.interceptors, interceptors...)
 	}
 
 }
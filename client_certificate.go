@@ -0,0 +1,148 @@
+package request
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+)
+
+// certificateReloader serves client certificate loaded from PEM files
+// and reloads it when files modification time changes.
+type certificateReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+	onError  func(error)
+	now      func() time.Time
+
+	mu          sync.Mutex
+	certificate *tls.Certificate
+	certModTime time.Time
+	keyModTime  time.Time
+	checkedAt   time.Time
+}
+
+func newCertificateReloader(
+	certFile string,
+	keyFile string,
+	interval time.Duration,
+	onError func(error),
+) *certificateReloader {
+	return &certificateReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+		onError:  onError,
+		now:      time.Now,
+	}
+
+}
+
+// GetClientCertificate implements tls.Config GetClientCertificate.
+// Files are checked for changes at most once per interval. If reload
+// fails, previously loaded certificate is served and onError is called.
+func (r *certificateReloader) GetClientCertificate(
+	*tls.CertificateRequestInfo,
+) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if r.certificate != nil &&
+		(r.interval <= 0 || now.Sub(r.checkedAt) < r.interval) {
+		return r.certificate, nil
+	}
+
+	r.checkedAt = now
+
+	certModTime, keyModTime, err := r.modTimes()
+	if err != nil {
+		return r.fail(err)
+	}
+
+	if r.certificate != nil &&
+		certModTime.Equal(r.certModTime) &&
+		keyModTime.Equal(r.keyModTime) {
+		return r.certificate, nil
+	}
+
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return r.fail(err)
+	}
+
+	r.certificate = &certificate
+	r.certModTime = certModTime
+	r.keyModTime = keyModTime
+
+	return r.certificate, nil
+
+}
+
+// modTimes returns certificate and key files modification time.
+func (r *certificateReloader) modTimes() (time.Time, time.Time, error) {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	return certInfo.ModTime(), keyInfo.ModTime(), nil
+
+}
+
+// fail reports reload error and falls back to previous certificate if any.
+func (r *certificateReloader) fail(err error) (*tls.Certificate, error) {
+	if r.onError != nil {
+		r.onError(err)
+	}
+
+	if r.certificate != nil {
+		return r.certificate, nil
+	}
+
+	return nil, err
+
+}
+
+// WithClientCertificate enables mutual TLS with certificate and key
+// loaded from given PEM files. Files are loaded once on first handshake,
+// load error is returned from request.
+func WithClientCertificate(certFile, keyFile string) func(*client) {
+	return func(c *client) {
+		c.clientCertificate = newCertificateReloader(
+			certFile,
+			keyFile,
+			0,
+			nil,
+		)
+	}
+
+}
+
+// WithReloadingClientCertificate enables mutual TLS with certificate and key
+// loaded from given PEM files. On handshake files are checked for changes
+// at most once per interval and reloaded when modified, so rotated
+// certificates are picked up without rebuilding Client. Failed reloads
+// are reported to onError, while previous certificate keeps being served.
+func WithReloadingClientCertificate(
+	certFile string,
+	keyFile string,
+	interval time.Duration,
+	onError func(error),
+) func(*client) {
+	return func(c *client) {
+		c.clientCertificate = newCertificateReloader(
+			certFile,
+			keyFile,
+			interval,
+			onError,
+		)
+	}
+
+}
@@ -0,0 +1,247 @@
+package request
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCertificateAuthority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCertificateAuthority(t *testing.T) *testCertificateAuthority {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCertificateAuthority{
+		cert: cert,
+		key:  key,
+	}
+
+}
+
+// writeClientCertificate issues client certificate with given common name
+// and writes it with its key to given PEM files.
+func (ca *testCertificateAuthority) writeClientCertificate(
+	t *testing.T,
+	commonName string,
+	certFile string,
+	keyFile string,
+) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(
+		t,
+		os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600),
+	)
+	require.NoError(
+		t,
+		os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600),
+	)
+
+}
+
+func TestWithClientCertificate(t *testing.T) {
+	ca := newTestCertificateAuthority(t)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+
+	ca.writeClientCertificate(t, "client", certFile, keyFile)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	server := httptest.NewUnstartedServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+			},
+		),
+	)
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+
+	type args struct {
+		certFile string
+		keyFile  string
+	}
+
+	type want struct {
+		commonName string
+		err        bool
+	}
+
+	type test struct {
+		name string
+		args args
+		want want
+	}
+
+	tests := []test{
+		{
+			name: "Valid certificate",
+			args: args{
+				certFile: certFile,
+				keyFile:  keyFile,
+			},
+			want: want{
+				commonName: "client",
+			},
+		},
+		{
+			name: "Missing certificate",
+			args: args{
+				certFile: filepath.Join(dir, "missing.crt"),
+				keyFile:  filepath.Join(dir, "missing.key"),
+			},
+			want: want{
+				err: true,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient(
+				WithRootCAs(rootCAs),
+				WithClientCertificate(tc.args.certFile, tc.args.keyFile),
+			)
+
+			res, err := c.Request().Get(context.Background(), server.URL)
+			if tc.want.err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			defer res.Body.Close()
+
+			body, err := io.ReadAll(res.Body)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want.commonName, string(body))
+
+		})
+	}
+
+}
+
+func TestCertificateReloader_GetClientCertificate(t *testing.T) {
+	ca := newTestCertificateAuthority(t)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+
+	ca.writeClientCertificate(t, "first", certFile, keyFile)
+
+	var reloadErrors []error
+
+	now := time.Now()
+
+	reloader := newCertificateReloader(
+		certFile,
+		keyFile,
+		time.Minute,
+		func(err error) {
+			reloadErrors = append(reloadErrors, err)
+		},
+	)
+	reloader.now = func() time.Time {
+		return now
+	}
+
+	commonName := func() string {
+		certificate, err := reloader.GetClientCertificate(nil)
+		require.NoError(t, err)
+
+		leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+		require.NoError(t, err)
+
+		return leaf.Subject.CommonName
+	}
+
+	assert.Equal(t, "first", commonName())
+
+	ca.writeClientCertificate(t, "second", certFile, keyFile)
+	modTime := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+
+	assert.Equal(t, "first", commonName(), "files are not checked before interval elapses")
+
+	now = now.Add(time.Minute)
+
+	assert.Equal(t, "second", commonName())
+
+	require.NoError(t, os.WriteFile(certFile, []byte("broken"), 0o600))
+	modTime = modTime.Add(time.Hour)
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+
+	now = now.Add(time.Minute)
+
+	assert.Equal(t, "second", commonName(), "previous certificate is served on failed reload")
+	assert.Len(t, reloadErrors, 1)
+	assert.False(t, errors.Is(reloadErrors[0], os.ErrNotExist))
+
+}
@@ -0,0 +1,128 @@
+package request
+
+import (
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"slices"
+)
+
+// requestDefaults describes which client defaults request opts out of.
+type requestDefaults struct {
+	skipAll     bool
+	skipHeaders []string
+	skipQueries []string
+}
+
+func (d requestDefaults) clone() requestDefaults {
+	return requestDefaults{
+		skipAll:     d.skipAll,
+		skipHeaders: slices.Clone(d.skipHeaders),
+		skipQueries: slices.Clone(d.skipQueries),
+	}
+
+}
+
+// applyDefaults merges client default HEADER and query into request ones.
+// Request values, including query already present in request URL, win,
+// so defaults are added only for missing keys.
+func (c *client) applyDefaults(
+	header http.Header,
+	query url.Values,
+	urlQuery url.Values,
+	defaults requestDefaults,
+) (http.Header, url.Values) {
+	if c == nil || defaults.skipAll {
+		return header, query
+	}
+
+	if len(c.defaultHeader) != 0 && header == nil {
+		header = make(http.Header, len(c.defaultHeader))
+	}
+
+	for key, values := range c.defaultHeader {
+		if _, ok := header[key]; ok {
+			continue
+		}
+
+		if slices.Contains(defaults.skipHeaders, key) {
+			continue
+		}
+
+		header[key] = slices.Clone(values)
+	}
+
+	if len(c.defaultQuery) != 0 && query == nil {
+		query = make(url.Values, len(c.defaultQuery))
+	}
+
+	for name, values := range c.defaultQuery {
+		if _, ok := query[name]; ok {
+			continue
+		}
+
+		if _, ok := urlQuery[name]; ok {
+			continue
+		}
+
+		if slices.Contains(defaults.skipQueries, name) {
+			continue
+		}
+
+		query[name] = slices.Clone(values)
+	}
+
+	return header, query
+
+}
+
+// WithDefaultHeaders sets HEADER added to every client request.
+// Request HEADER with the same key wins.
+func WithDefaultHeaders(values map[string][]string) func(*client) {
+	return func(c *client) {
+		if c.defaultHeader == nil {
+			c.defaultHeader = make(http.Header, len(values))
+		}
+
+		for key, items := range values {
+			c.defaultHeader[textproto.CanonicalMIMEHeaderKey(key)] = slices.Clone(items)
+		}
+	}
+
+}
+
+// WithDefaultQuery sets query parameters added to every client request.
+// Request query parameter with the same name wins.
+func WithDefaultQuery(values map[string][]string) func(*client) {
+	return func(c *client) {
+		if c.defaultQuery == nil {
+			c.defaultQuery = make(url.Values, len(values))
+		}
+
+		for name, items := range values {
+			c.defaultQuery[name] = slices.Clone(items)
+		}
+	}
+
+}
+
+// WithUserAgent sets User-Agent HEADER of every client request.
+func WithUserAgent(userAgent string) func(*client) {
+	return WithDefaultHeaders(
+		map[string][]string{
+			UserAgent: {userAgent},
+		},
+	)
+
+}
+
+// WithDefaultAuth sets authorization HEADER values of every client request,
+// e.g. "Bearer token". Request authorization HEADER wins.
+func WithDefaultAuth(values ...string) func(*client) {
+	return WithDefaultHeaders(
+		map[string][]string{
+			Authorization: values,
+		},
+	)
+
+}
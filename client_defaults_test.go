@@ -0,0 +1,142 @@
+package request
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestClient_Defaults(t *testing.T) {
+	type args struct {
+		req func(Request) Request
+		url string
+	}
+
+	type want struct {
+		header http.Header
+		query  url.Values
+	}
+
+	type test struct {
+		name string
+		args args
+		want want
+	}
+
+	tests := []test{
+		{
+			name: "Defaults are added",
+			args: args{
+				req: func(req Request) Request { return req },
+				url: "http://localhost:8080",
+			},
+			want: want{
+				header: http.Header{
+					UserAgent:     {"test-agent/1.0"},
+					Authorization: {"Bearer default"},
+					"Accept":      {ApplicationJSON},
+				},
+				query: url.Values{"api-version": {"2"}},
+			},
+		},
+		{
+			name: "Request values win",
+			args: args{
+				req: func(req Request) Request {
+					return req.
+						WithBearerAuth("request").
+						WithHeader("accept", ApplicationXML).
+						WithQuery("api-version", "3")
+				},
+				url: "http://localhost:8080",
+			},
+			want: want{
+				header: http.Header{
+					UserAgent:     {"test-agent/1.0"},
+					Authorization: {"Bearer request"},
+					"Accept":      {ApplicationXML},
+				},
+				query: url.Values{"api-version": {"3"}},
+			},
+		},
+		{
+			name: "URL query wins",
+			args: args{
+				req: func(req Request) Request { return req },
+				url: "http://localhost:8080?api-version=1",
+			},
+			want: want{
+				header: http.Header{
+					UserAgent:     {"test-agent/1.0"},
+					Authorization: {"Bearer default"},
+					"Accept":      {ApplicationJSON},
+				},
+				query: url.Values{"api-version": {"1"}},
+			},
+		},
+		{
+			name: "Opt out of single defaults",
+			args: args{
+				req: func(req Request) Request {
+					return req.
+						WithoutDefaultHeader("authorization").
+						WithoutDefaultQuery("api-version")
+				},
+				url: "http://localhost:8080",
+			},
+			want: want{
+				header: http.Header{
+					UserAgent: {"test-agent/1.0"},
+					"Accept":  {ApplicationJSON},
+				},
+				query: url.Values{},
+			},
+		},
+		{
+			name: "Opt out of all defaults",
+			args: args{
+				req: func(req Request) Request {
+					return req.WithoutDefaults().WithHeader("X-Id", "1")
+				},
+				url: "http://localhost:8080",
+			},
+			want: want{
+				header: http.Header{"X-Id": {"1"}},
+				query:  url.Values{},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got *http.Request
+
+			c := NewClient(
+				WithUserAgent("test-agent/1.0"),
+				WithDefaultAuth("Bearer default"),
+				WithDefaultHeaders(map[string][]string{"accept": {ApplicationJSON}}),
+				WithDefaultQuery(map[string][]string{"api-version": {"2"}}),
+				WithTransport(
+					RoundTripper(
+						func(req *http.Request) (*http.Response, error) {
+							got = req
+
+							return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+						},
+					),
+				),
+			)
+
+			_, err := tc.args.req(c.Request()).Get(context.Background(), tc.args.url)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.want.header, got.Header)
+			assert.Equal(t, tc.want.query, got.URL.Query())
+
+		})
+	}
+
+}
@@ -0,0 +1,242 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+const (
+	DefaultDialTimeout = 30 * time.Second
+	DefaultKeepAlive   = 30 * time.Second
+)
+
+// dialContext dials address using client dialer options. Address is
+// replaced with static resolution if any, so TLS server name and
+// Host header still use original host.
+func (c *client) dialContext(
+	ctx context.Context,
+	network string,
+	address string,
+) (net.Conn, error) {
+	dialer := &net.Dialer{
+		Timeout:   c.dialTimeout,
+		KeepAlive: c.keepAlive,
+		Resolver:  c.resolver,
+	}
+
+	if c.unixSocket != "" {
+		return dialer.DialContext(ctx, unixScheme, c.unixSocket)
+	}
+
+	if c.localAddressErr != nil {
+		return nil, c.localAddressErr
+	}
+
+	if c.localInterface != "" {
+		localAddress, err := interfaceAddress(c.localInterface, network)
+		if err != nil {
+			return nil, err
+		}
+
+		dialer.LocalAddr = localAddress
+	} else if c.localAddress != nil {
+		dialer.LocalAddr = &net.TCPAddr{IP: c.localAddress}
+	}
+
+	addresses := c.resolveAddress(address)
+
+	var errs []error
+	for _, addr := range addresses {
+		conn, err := dialer.DialContext(ctx, network, addr)
+		if err == nil {
+			return conn, nil
+		}
+
+		errs = append(errs, err)
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, errors.Join(errs...)
+
+}
+
+// resolveAddress returns addresses to dial for given "host:port" address.
+// Static resolution by "host:port" has higher priority than by "host".
+func (c *client) resolveAddress(address string) []string {
+	if len(c.resolve) == 0 {
+		return []string{address}
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return []string{address}
+	}
+
+	ips, ok := c.resolve[address]
+	if !ok {
+		ips, ok = c.resolve[host]
+	}
+
+	if !ok {
+		return []string{address}
+	}
+
+	addresses := make([]string, len(ips))
+	for i, ip := range ips {
+		addresses[i] = net.JoinHostPort(ip, port)
+	}
+
+	return addresses
+
+}
+
+// lookupAddress returns IP addresses of "host:port" address host with
+// static resolution if any, otherwise with client resolver.
+func (c *client) lookupAddress(ctx context.Context, address string) ([]net.IP, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if addresses := c.resolveAddress(address); len(addresses) != 1 || addresses[0] != address {
+		ips := make([]net.IP, 0, len(addresses))
+		for _, addr := range addresses {
+			ipString, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+
+			ip := net.ParseIP(ipString)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid resolve address %q of %q", ipString, host)
+			}
+
+			ips = append(ips, ip)
+		}
+
+		return ips, nil
+	}
+
+	resolver := c.resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	return resolver.LookupIP(ctx, "ip", host)
+
+}
+
+// interfaceAddress returns first address of given network interface
+// matching network family. IPv4 is preferred for dual stack networks.
+func interfaceAddress(name string, network string) (net.Addr, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+
+	var ipv6 net.IP
+	for _, address := range addresses {
+		ipNet, ok := address.(*net.IPNet)
+		if !ok {
+			continue
+		}
+
+		if ipNet.IP.To4() != nil {
+			if network != "tcp6" {
+				return &net.TCPAddr{IP: ipNet.IP}, nil
+			}
+
+			continue
+		}
+
+		if ipv6 == nil {
+			ipv6 = ipNet.IP
+		}
+	}
+
+	if ipv6 != nil && network != "tcp4" {
+		return &net.TCPAddr{IP: ipv6}, nil
+	}
+
+	return nil, fmt.Errorf("interface %s has no %s address", name, network)
+
+}
+
+// WithDialTimeout sets maximum amount of time a dial waits for connect
+// to complete. If not provided, DefaultDialTimeout is used.
+func WithDialTimeout(dialTimeout time.Duration) func(*client) {
+	return func(c *client) {
+		c.dialTimeout = dialTimeout
+	}
+
+}
+
+// WithKeepAlive sets interval between keep-alive probes of active
+// connections. Negative value disables keep-alive probes.
+// If not provided, DefaultKeepAlive is used.
+func WithKeepAlive(keepAlive time.Duration) func(*client) {
+	return func(c *client) {
+		c.keepAlive = keepAlive
+	}
+
+}
+
+// WithResolver sets resolver used to look up host names, including
+// target host sent to socks5:// proxy. If not provided,
+// net.DefaultResolver is used.
+func WithResolver(resolver *net.Resolver) func(*client) {
+	return func(c *client) {
+		c.resolver = resolver
+	}
+
+}
+
+// WithResolve maps "host:port" or "host" to given IP addresses like
+// curl --resolve does. Connections are made to given addresses in order,
+// while TLS server name and Host header keep original host. With socks5://
+// proxy the first address is sent to proxy as target.
+func WithResolve(host string, addresses ...string) func(*client) {
+	return func(c *client) {
+		if c.resolve == nil {
+			c.resolve = make(map[string][]string)
+		}
+
+		c.resolve[host] = addresses
+	}
+
+}
+
+// WithLocalAddress binds outgoing connections to given local IP address.
+// Invalid address fails dial instead of connecting from any address.
+func WithLocalAddress(address string) func(*client) {
+	return func(c *client) {
+		ip := net.ParseIP(address)
+		if ip == nil {
+			c.localAddress, c.localAddressErr = nil, fmt.Errorf("invalid local address %q", address)
+			return
+		}
+
+		c.localAddress, c.localAddressErr = ip, nil
+	}
+
+}
+
+// WithLocalInterface binds outgoing connections to address
+// of given network interface, e.g. "eth0".
+func WithLocalInterface(name string) func(*client) {
+	return func(c *client) {
+		c.localInterface = name
+	}
+
+}
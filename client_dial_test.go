@@ -0,0 +1,145 @@
+package request
+
+import (
+	"context"
+	"crypto/x509"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestWithResolve(t *testing.T) {
+	handler := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			host, _, _ := net.SplitHostPort(r.RemoteAddr)
+			w.Header().Set("X-Remote-Host", host)
+			_, _ = w.Write([]byte(r.Host))
+		},
+	)
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	tlsServer := httptest.NewTLSServer(handler)
+	defer tlsServer.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(tlsServer.Certificate())
+
+	serverURL, _ := url.Parse(server.URL)
+	tlsServerURL, _ := url.Parse(tlsServer.URL)
+
+	type args struct {
+		url     string
+		options []func(*client)
+	}
+
+	type want struct {
+		host       string
+		remoteHost string
+		err        bool
+	}
+
+	type test struct {
+		name string
+		args args
+		want want
+	}
+
+	tests := []test{
+		{
+			name: "Host and port",
+			args: args{
+				url: "http://api.test:" + serverURL.Port(),
+				options: []func(*client){
+					WithResolve("api.test:"+serverURL.Port(), "127.0.0.1"),
+				},
+			},
+			want: want{
+				host: "api.test:" + serverURL.Port(),
+			},
+		},
+		{
+			name: "Host only with fallback address",
+			args: args{
+				url: "http://api.test:" + serverURL.Port(),
+				options: []func(*client){
+					WithResolve("api.test", "127.0.0.2", "127.0.0.1"),
+					WithLocalAddress("127.0.0.1"),
+				},
+			},
+			want: want{
+				host:       "api.test:" + serverURL.Port(),
+				remoteHost: "127.0.0.1",
+			},
+		},
+		{
+			name: "TLS keeps server name",
+			args: args{
+				url: "https://example.com:" + tlsServerURL.Port(),
+				options: []func(*client){
+					WithRootCAs(rootCAs),
+					WithResolve("example.com:"+tlsServerURL.Port(), "127.0.0.1"),
+				},
+			},
+			want: want{
+				host: "example.com:" + tlsServerURL.Port(),
+			},
+		},
+		{
+			name: "Invalid local address",
+			args: args{
+				url: server.URL,
+				options: []func(*client){
+					WithLocalAddress("127.0.0.300"),
+				},
+			},
+			want: want{
+				err: true,
+			},
+		},
+		{
+			name: "Missing interface",
+			args: args{
+				url: server.URL,
+				options: []func(*client){
+					WithLocalInterface("missing0"),
+				},
+			},
+			want: want{
+				err: true,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := NewClient(tc.args.options...).
+				Request().
+				Get(context.Background(), tc.args.url)
+			if tc.want.err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			body, err := io.ReadAll(res.Body)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want.host, string(body))
+
+			if tc.want.remoteHost != "" {
+				assert.Equal(t, tc.want.remoteHost, res.Header.Get("X-Remote-Host"))
+			}
+
+		})
+	}
+
+}
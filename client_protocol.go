@@ -0,0 +1,178 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const unixScheme = "unix"
+
+// protocolTransport routes requests to transports registered
+// by URL scheme, other requests go through base transport.
+type protocolTransport struct {
+	transport http.RoundTripper
+
+	mu        sync.RWMutex
+	protocols map[string]http.RoundTripper
+}
+
+func newProtocolTransport(transport http.RoundTripper) *protocolTransport {
+	return &protocolTransport{
+		transport: transport,
+		protocols: make(map[string]http.RoundTripper),
+	}
+
+}
+
+func (t *protocolTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL != nil {
+		t.mu.RLock()
+		transport, ok := t.protocols[strings.ToLower(req.URL.Scheme)]
+		t.mu.RUnlock()
+
+		if ok {
+			return transport.RoundTrip(req)
+		}
+	}
+
+	return t.transport.RoundTrip(req)
+
+}
+
+func (t *protocolTransport) register(scheme string, transport http.RoundTripper) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.protocols[strings.ToLower(scheme)] = transport
+
+}
+
+// CloseIdleConnections closes idle connections of all transports.
+func (t *protocolTransport) CloseIdleConnections() {
+	closeIdleConnections(t.transport)
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, transport := range t.protocols {
+		closeIdleConnections(transport)
+	}
+
+}
+
+func closeIdleConnections(transport http.RoundTripper) {
+	type closeIdler interface {
+		CloseIdleConnections()
+	}
+
+	if transport, ok := transport.(closeIdler); ok {
+		transport.CloseIdleConnections()
+	}
+
+}
+
+// RegisterProtocol registers transport serving requests with given URL
+// scheme, e.g. in-house schemes. Registered transport is wrapped
+// by client interceptors like any other request.
+func (c *client) RegisterProtocol(scheme string, transport http.RoundTripper) {
+	c.protocolTransport.register(scheme, transport)
+}
+
+// unixTransport serves unix:// URLs in form
+// "unix:///path/to/socket:/request/path" over HTTP on Unix domain socket.
+type unixTransport struct {
+	newTransport func() *http.Transport
+
+	mu         sync.Mutex
+	transports map[string]*http.Transport
+}
+
+func newUnixTransport(newTransport func() *http.Transport) *unixTransport {
+	return &unixTransport{
+		newTransport: newTransport,
+		transports:   make(map[string]*http.Transport),
+	}
+
+}
+
+func (t *unixTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	socket, path, ok := strings.Cut(req.URL.Path, ":")
+	if !ok || socket == "" {
+		closeRequestBody(req)
+		return nil, fmt.Errorf("unix: missing socket path separator in %q", req.URL.Path)
+	}
+
+	if path == "" {
+		path = "/"
+	}
+
+	unixReq := req.Clone(req.Context())
+	unixReq.URL.Scheme = "http"
+	unixReq.URL.Host = "localhost"
+	unixReq.URL.Path = path
+	unixReq.URL.RawPath = ""
+	unixReq.Host = "localhost"
+
+	res, err := t.transport(socket).RoundTrip(unixReq)
+	if res != nil {
+		res.Request = req
+	}
+
+	return res, err
+
+}
+
+// transport returns transport dialing given socket.
+// Transports are cached per socket to reuse connections.
+func (t *unixTransport) transport(socket string) *http.Transport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if transport, ok := t.transports[socket]; ok {
+		return transport
+	}
+
+	transport := t.newTransport()
+	transport.Proxy = nil
+	transport.DialContext = unixDialer(socket)
+
+	t.transports[socket] = transport
+
+	return transport
+
+}
+
+// CloseIdleConnections closes idle connections of all socket transports.
+func (t *unixTransport) CloseIdleConnections() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, transport := range t.transports {
+		transport.CloseIdleConnections()
+	}
+
+}
+
+// unixDialer returns dial function connecting to given socket
+// regardless of requested address.
+func unixDialer(socket string) func(context.Context, string, string) (net.Conn, error) {
+	return func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var dialer net.Dialer
+
+		return dialer.DialContext(ctx, unixScheme, socket)
+	}
+
+}
+
+// WithUnixSocket sends all client requests over HTTP
+// on given Unix domain socket, URL host is ignored.
+func WithUnixSocket(socket string) func(*client) {
+	return func(c *client) {
+		c.unixSocket = socket
+	}
+
+}
@@ -0,0 +1,153 @@
+package request
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+// newTestUnixServer serves HTTP on Unix domain socket
+// and responds with request path and query.
+func newTestUnixServer(t *testing.T) string {
+	socket := filepath.Join(t.TempDir(), "test.sock")
+
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	server := &http.Server{
+		Handler: http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(r.URL.RequestURI()))
+			},
+		),
+	}
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	t.Cleanup(func() {
+		_ = server.Close()
+	})
+
+	return socket
+
+}
+
+func TestClient_UnixSocket(t *testing.T) {
+	socket := newTestUnixServer(t)
+
+	type args struct {
+		url     string
+		options []func(*client)
+	}
+
+	type want struct {
+		body string
+		err  bool
+	}
+
+	type test struct {
+		name string
+		args args
+		want want
+	}
+
+	tests := []test{
+		{
+			name: "Unix URL",
+			args: args{
+				url: "unix://" + socket + ":/v1.43/containers/json",
+			},
+			want: want{
+				body: "/v1.43/containers/json?all=true",
+			},
+		},
+		{
+			name: "Unix URL without separator",
+			args: args{
+				url: "unix://" + socket,
+			},
+			want: want{
+				err: true,
+			},
+		},
+		{
+			name: "WithUnixSocket",
+			args: args{
+				url: "http://docker/v1.43/containers/json",
+				options: []func(*client){
+					WithUnixSocket(socket),
+				},
+			},
+			want: want{
+				body: "/v1.43/containers/json?all=true",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := NewClient(tc.args.options...).
+				Request().
+				WithQuery("all", "true").
+				Get(context.Background(), tc.args.url)
+			if tc.want.err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			body, err := io.ReadAll(res.Body)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want.body, string(body))
+
+		})
+	}
+
+}
+
+func TestClient_RegisterProtocol(t *testing.T) {
+	var intercepted bool
+
+	c := NewClient(
+		WithInterceptors(
+			func(tripper http.RoundTripper) http.RoundTripper {
+				return RoundTripper(
+					func(req *http.Request) (*http.Response, error) {
+						intercepted = true
+						return tripper.RoundTrip(req)
+					},
+				)
+			},
+		),
+	)
+
+	c.RegisterProtocol(
+		"mem",
+		RoundTripper(
+			func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(http.NoBody),
+					Request:    req,
+				}, nil
+			},
+		),
+	)
+
+	res, err := c.Request().Get(context.Background(), "mem://storage/key")
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.True(t, intercepted)
+
+}
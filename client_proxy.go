@@ -0,0 +1,252 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+type proxyContextKey struct{}
+
+// withProxyContext sets request level proxy override to context.
+func withProxyContext(ctx context.Context, proxyURL *url.URL) context.Context {
+	return context.WithValue(ctx, proxyContextKey{}, proxyURL)
+}
+
+// proxy resolves proxy for given request. Request level proxy has
+// the highest priority, then client proxy unless request host matches
+// NO_PROXY rules, then proxy from environment.
+func (c *client) proxy(req *http.Request) (*url.URL, error) {
+	if proxyURL, ok := req.Context().Value(proxyContextKey{}).(*url.URL); ok {
+		return proxyURL, nil
+	}
+
+	if c.unixSocket != "" || bypassProxy(req.URL, c.noProxy) {
+		return nil, nil
+	}
+
+	if c.proxyFunc != nil {
+		return c.proxyFunc(req)
+	}
+
+	return http.ProxyFromEnvironment(req)
+
+}
+
+// parseProxyURL parses proxy URL and validates its scheme.
+// Empty value means direct connection.
+func parseProxyURL(value string) (*url.URL, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	proxyURL, err := url.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return proxyURL, nil
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", proxyURL.Scheme)
+	}
+
+}
+
+// bypassProxy checks whether URL host matches any of NO_PROXY rules.
+// Rule is either "*", IP address, CIDR, or host name matching itself
+// and its subdomains. Rule may contain port, then port must match too.
+func bypassProxy(u *url.URL, rules []string) bool {
+	if u == nil || len(rules) == 0 {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if port == "" {
+		switch u.Scheme {
+		case "https":
+			port = "443"
+		case "http":
+			port = "80"
+		}
+	}
+
+	ip := net.ParseIP(host)
+
+	for _, rule := range rules {
+		rule = strings.ToLower(strings.TrimSpace(rule))
+		if rule == "" {
+			continue
+		}
+
+		if rule == "*" {
+			return true
+		}
+
+		if _, network, err := net.ParseCIDR(rule); err == nil {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+
+			continue
+		}
+
+		ruleHost, rulePort := rule, ""
+		if h, p, err := net.SplitHostPort(rule); err == nil {
+			ruleHost, rulePort = h, p
+		}
+
+		if rulePort != "" && rulePort != port {
+			continue
+		}
+
+		if ruleIP := net.ParseIP(ruleHost); ruleIP != nil {
+			if ip != nil && ruleIP.Equal(ip) {
+				return true
+			}
+
+			continue
+		}
+
+		ruleHost = strings.TrimPrefix(ruleHost, "*")
+		ruleHost = strings.TrimPrefix(ruleHost, ".")
+
+		if host == ruleHost || strings.HasSuffix(host, "."+ruleHost) {
+			return true
+		}
+	}
+
+	return false
+
+}
+
+// proxyTransport routes requests with SOCKS5 proxy through dedicated
+// transports using in-package SOCKS5 dialer, other requests go
+// through base transport which handles HTTP and HTTPS proxies itself.
+type proxyTransport struct {
+	transport *http.Transport
+	proxy     func(*http.Request) (*url.URL, error)
+	lookup    func(ctx context.Context, address string) ([]net.IP, error)
+
+	mu         sync.Mutex
+	transports map[string]*http.Transport
+}
+
+func newProxyTransport(
+	transport *http.Transport,
+	proxy func(*http.Request) (*url.URL, error),
+	lookup func(ctx context.Context, address string) ([]net.IP, error),
+) *proxyTransport {
+	transport.Proxy = proxy
+
+	return &proxyTransport{
+		transport:  transport,
+		proxy:      proxy,
+		lookup:     lookup,
+		transports: make(map[string]*http.Transport),
+	}
+
+}
+
+func (t *proxyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	proxyURL, err := t.proxy(req)
+	if err != nil {
+		closeRequestBody(req)
+		return nil, err
+	}
+
+	if proxyURL == nil ||
+		(proxyURL.Scheme != "socks5" && proxyURL.Scheme != "socks5h") {
+		return t.transport.RoundTrip(req)
+	}
+
+	return t.socks5Transport(proxyURL).RoundTrip(req)
+
+}
+
+// socks5Transport returns transport dialing through given SOCKS5 proxy.
+// Transports are cached per proxy URL to reuse connections.
+func (t *proxyTransport) socks5Transport(proxyURL *url.URL) *http.Transport {
+	key := proxyURL.String()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if transport, ok := t.transports[key]; ok {
+		return transport
+	}
+
+	transport := t.transport.Clone()
+	transport.Proxy = nil
+	transport.DialContext = newSOCKS5Dialer(
+		proxyURL,
+		t.transport.DialContext,
+		t.lookup,
+	).DialContext
+
+	t.transports[key] = transport
+
+	return transport
+
+}
+
+// CloseIdleConnections closes idle connections of all transports.
+func (t *proxyTransport) CloseIdleConnections() {
+	t.transport.CloseIdleConnections()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, transport := range t.transports {
+		transport.CloseIdleConnections()
+	}
+
+}
+
+// closeRequestBody closes request body as http.RoundTripper must do on error.
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+
+}
+
+// WithProxy routes client requests through given proxy URL.
+// Supported schemes are http, https, socks5 and socks5h, credentials
+// are taken from URL user info. Empty URL disables proxy from environment.
+// If not provided, proxy from environment is used.
+func WithProxy(proxyURL string) func(*client) {
+	return func(c *client) {
+		u, err := parseProxyURL(proxyURL)
+
+		c.proxyFunc = func(*http.Request) (*url.URL, error) {
+			return u, err
+		}
+	}
+
+}
+
+// WithProxyFunc sets function returning proxy URL for given request.
+// Nil URL means direct connection.
+func WithProxyFunc(proxyFunc func(*http.Request) (*url.URL, error)) func(*client) {
+	return func(c *client) {
+		c.proxyFunc = proxyFunc
+	}
+
+}
+
+// WithNoProxy sets rules for hosts connected directly bypassing proxy.
+// Rule is either "*", IP address, CIDR, or host name matching itself
+// and its subdomains, optionally with port.
+func WithNoProxy(rules ...string) func(*client) {
+	return func(c *client) {
+		c.noProxy = append(c.noProxy, rules...)
+	}
+
+}
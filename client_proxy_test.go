@@ -0,0 +1,434 @@
+package request
+
+import (
+	"context"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// newTestHTTPProxy starts plain HTTP forward proxy counting proxied requests.
+func newTestHTTPProxy(t *testing.T, hits *atomic.Int32) *httptest.Server {
+	proxy := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				hits.Add(1)
+
+				if auth := r.Header.Get("Proxy-Authorization"); auth != "" {
+					w.Header().Set("X-Proxy-Authorization", auth)
+				}
+
+				req, err := http.NewRequestWithContext(r.Context(), r.Method, r.URL.String(), r.Body)
+				if err != nil {
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+
+				res, err := http.DefaultTransport.RoundTrip(req)
+				if err != nil {
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+				defer res.Body.Close()
+
+				w.Header().Set("X-Proxied", "true")
+				w.WriteHeader(res.StatusCode)
+				_, _ = io.Copy(w, res.Body)
+			},
+		),
+	)
+	t.Cleanup(proxy.Close)
+
+	return proxy
+
+}
+
+// newTestSOCKS5Proxy starts SOCKS5 proxy requiring given credentials
+// and counting proxied connections.
+func newTestSOCKS5Proxy(t *testing.T, username, password string, hits *atomic.Int32) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	serve := func(conn net.Conn) {
+		defer conn.Close()
+
+		header := make([]byte, 2)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+
+		if _, err := io.ReadFull(conn, make([]byte, header[1])); err != nil {
+			return
+		}
+
+		_, _ = conn.Write([]byte{socks5Version, socks5AuthPassword})
+
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+
+		user := make([]byte, header[1])
+		_, _ = io.ReadFull(conn, user)
+		_, _ = io.ReadFull(conn, header[:1])
+		pass := make([]byte, header[0])
+		_, _ = io.ReadFull(conn, pass)
+
+		if string(user) != username || string(pass) != password {
+			_, _ = conn.Write([]byte{socks5PasswordVersion, 0x01})
+			return
+		}
+
+		_, _ = conn.Write([]byte{socks5PasswordVersion, 0x00})
+
+		request := make([]byte, 4)
+		if _, err := io.ReadFull(conn, request); err != nil {
+			return
+		}
+
+		var host string
+		switch request[3] {
+		case socks5AddressIPv4:
+			ip := make([]byte, net.IPv4len)
+			_, _ = io.ReadFull(conn, ip)
+			host = net.IP(ip).String()
+		case socks5AddressDomain:
+			_, _ = io.ReadFull(conn, header[:1])
+			domain := make([]byte, header[0])
+			_, _ = io.ReadFull(conn, domain)
+			host = string(domain)
+		default:
+			return
+		}
+
+		port := make([]byte, 2)
+		_, _ = io.ReadFull(conn, port)
+
+		target, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))))
+		if err != nil {
+			_, _ = conn.Write([]byte{socks5Version, 0x05, 0x00, socks5AddressIPv4, 0, 0, 0, 0, 0, 0})
+			return
+		}
+		defer target.Close()
+
+		hits.Add(1)
+
+		_, _ = conn.Write([]byte{socks5Version, 0x00, 0x00, socks5AddressIPv4, 0, 0, 0, 0, 0, 0})
+
+		go func() {
+			_, _ = io.Copy(target, conn)
+		}()
+		_, _ = io.Copy(conn, target)
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go serve(conn)
+		}
+	}()
+
+	return listener
+
+}
+
+func TestClient_Proxy(t *testing.T) {
+	target := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			},
+		),
+	)
+	defer target.Close()
+
+	var httpHits, socksHits atomic.Int32
+
+	httpProxy := newTestHTTPProxy(t, &httpHits)
+	socksProxy := newTestSOCKS5Proxy(t, "user", "secret", &socksHits)
+
+	httpProxyURL, _ := url.Parse(httpProxy.URL)
+	httpProxyURL.User = url.UserPassword("user", "secret")
+
+	type args struct {
+		options []func(*client)
+		proxy   string
+	}
+
+	type want struct {
+		httpHits  int32
+		socksHits int32
+		err       bool
+	}
+
+	type test struct {
+		name string
+		args args
+		want want
+	}
+
+	tests := []test{
+		{
+			name: "WithProxy HTTP",
+			args: args{
+				options: []func(*client){
+					WithProxy(httpProxyURL.String()),
+				},
+			},
+			want: want{
+				httpHits: 1,
+			},
+		},
+		{
+			name: "WithProxy SOCKS5",
+			args: args{
+				options: []func(*client){
+					WithProxy("socks5://user:secret@" + socksProxy.Addr().String()),
+				},
+			},
+			want: want{
+				socksHits: 1,
+			},
+		},
+		{
+			name: "WithProxy SOCKS5 wrong credentials",
+			args: args{
+				options: []func(*client){
+					WithProxy("socks5h://user:wrong@" + socksProxy.Addr().String()),
+				},
+			},
+			want: want{
+				err: true,
+			},
+		},
+		{
+			name: "WithProxyFunc",
+			args: args{
+				options: []func(*client){
+					WithProxyFunc(http.ProxyURL(httpProxyURL)),
+				},
+			},
+			want: want{
+				httpHits: 1,
+			},
+		},
+		{
+			name: "WithNoProxy CIDR",
+			args: args{
+				options: []func(*client){
+					WithProxy(httpProxy.URL),
+					WithNoProxy("127.0.0.0/8"),
+				},
+			},
+		},
+		{
+			name: "Request WithProxy overrides client",
+			args: args{
+				options: []func(*client){
+					WithProxy(""),
+				},
+				proxy: "socks5://user:secret@" + socksProxy.Addr().String(),
+			},
+			want: want{
+				socksHits: 1,
+			},
+		},
+		{
+			name: "Unsupported proxy scheme",
+			args: args{
+				options: []func(*client){
+					WithProxy("ftp://localhost"),
+				},
+			},
+			want: want{
+				err: true,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			httpHits.Store(0)
+			socksHits.Store(0)
+
+			res, err := NewClient(tc.args.options...).
+				Request().
+				WithProxy(tc.args.proxy).
+				Get(context.Background(), target.URL)
+			if tc.want.err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			_ = res.Body.Close()
+
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+			assert.Equal(t, tc.want.httpHits, httpHits.Load())
+			assert.Equal(t, tc.want.socksHits, socksHits.Load())
+
+			if tc.want.httpHits != 0 {
+				assert.Equal(t, "true", res.Header.Get("X-Proxied"))
+				assert.Equal(
+					t,
+					"Basic dXNlcjpzZWNyZXQ=",
+					res.Header.Get("X-Proxy-Authorization"),
+				)
+			}
+
+		})
+	}
+
+}
+
+func TestClient_ProxySOCKS5Resolve(t *testing.T) {
+	target := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(r.Host))
+			},
+		),
+	)
+	defer target.Close()
+
+	_, port, err := net.SplitHostPort(target.Listener.Addr().String())
+	require.NoError(t, err)
+
+	var socksHits atomic.Int32
+
+	socksProxy := newTestSOCKS5Proxy(t, "user", "secret", &socksHits)
+
+	type args struct {
+		resolve string
+	}
+
+	type test struct {
+		name string
+		args args
+	}
+
+	tests := []test{
+		{
+			name: "WithResolve host",
+			args: args{
+				resolve: "api.example.invalid",
+			},
+		},
+		{
+			name: "WithResolve host and port",
+			args: args{
+				resolve: "api.example.invalid:" + port,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			socksHits.Store(0)
+
+			res, err := NewClient(
+				WithProxy("socks5://user:secret@"+socksProxy.Addr().String()),
+				WithResolve(tc.args.resolve, "127.0.0.1"),
+			).
+				Request().
+				Get(context.Background(), "http://api.example.invalid:"+port)
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, "api.example.invalid:"+port, string(body))
+			assert.Equal(t, int32(1), socksHits.Load())
+
+		})
+	}
+
+}
+
+func TestBypassProxy(t *testing.T) {
+	type args struct {
+		url   string
+		rules []string
+	}
+
+	type want struct {
+		bypass bool
+	}
+
+	type test struct {
+		name string
+		args args
+		want want
+	}
+
+	tests := []test{
+		{
+			name: "Without rules",
+			args: args{url: "http://example.com"},
+		},
+		{
+			name: "Wildcard",
+			args: args{url: "http://example.com", rules: []string{"*"}},
+			want: want{bypass: true},
+		},
+		{
+			name: "Exact host",
+			args: args{url: "http://example.com", rules: []string{"example.com"}},
+			want: want{bypass: true},
+		},
+		{
+			name: "Subdomain",
+			args: args{url: "http://api.example.com", rules: []string{".example.com"}},
+			want: want{bypass: true},
+		},
+		{
+			name: "Other domain",
+			args: args{url: "http://notexample.com", rules: []string{"example.com"}},
+		},
+		{
+			name: "Host with matching port",
+			args: args{url: "https://example.com", rules: []string{"example.com:443"}},
+			want: want{bypass: true},
+		},
+		{
+			name: "Host with other port",
+			args: args{url: "http://example.com", rules: []string{"example.com:443"}},
+		},
+		{
+			name: "CIDR",
+			args: args{url: "http://10.1.2.3:8080", rules: []string{"10.0.0.0/8"}},
+			want: want{bypass: true},
+		},
+		{
+			name: "IPv6",
+			args: args{url: "http://[::1]:8080", rules: []string{"::1"}},
+			want: want{bypass: true},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			u, err := url.Parse(tc.args.url)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.want.bypass, bypassProxy(u, tc.args.rules))
+
+		})
+	}
+
+}
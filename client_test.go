@@ -29,15 +29,14 @@ func TestNewClient(t *testing.T) {
 			args: args{},
 			want: want{
 				client: &client{
-					httpClient: &http.Client{
-						Transport: http.DefaultTransport,
-					},
 					timeout:                   DefaultTimeout,
 					idleConnectionTimeout:     DefaultIdleConnectionTimeout,
 					maxIdleConnections:        DefaultMaxIdleConnections,
 					maxConnectionsPerHost:     DefaultMaxConnectionsPerHost,
 					maxIdleConnectionsPerHost: DefaultMaxIdleConnectionsPerHost,
 					forceAttemptHTTP2:         DefaultForceAttemptHTTP2,
+					dialTimeout:               DefaultDialTimeout,
+					keepAlive:                 DefaultKeepAlive,
 				},
 			},
 		},
@@ -50,15 +49,14 @@ func TestNewClient(t *testing.T) {
 			},
 			want: want{
 				client: &client{
-					httpClient: &http.Client{
-						Transport: http.DefaultTransport,
-					},
 					timeout:                   time.Second,
 					idleConnectionTimeout:     DefaultIdleConnectionTimeout,
 					maxIdleConnections:        DefaultMaxIdleConnections,
 					maxConnectionsPerHost:     DefaultMaxConnectionsPerHost,
 					maxIdleConnectionsPerHost: DefaultMaxIdleConnectionsPerHost,
 					forceAttemptHTTP2:         DefaultForceAttemptHTTP2,
+					dialTimeout:               DefaultDialTimeout,
+					keepAlive:                 DefaultKeepAlive,
 				},
 			},
 		},
@@ -66,14 +64,65 @@ func TestNewClient(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			client := NewClient(tc.args.options...)
+			c := NewClient(tc.args.options...).(*client)
+
+			transport := c.httpTransport
+
+			assert.NotNil(t, transport)
+			assert.NotSame(t, http.DefaultTransport, transport)
+			assert.Equal(t, tc.want.client.maxIdleConnections, transport.MaxIdleConns)
+			assert.Equal(t, tc.want.client.maxConnectionsPerHost, transport.MaxConnsPerHost)
+			assert.Equal(t, tc.want.client.maxIdleConnectionsPerHost, transport.MaxIdleConnsPerHost)
+			assert.Equal(t, tc.want.client.idleConnectionTimeout, transport.IdleConnTimeout)
+			assert.Equal(t, tc.want.client.forceAttemptHTTP2, transport.ForceAttemptHTTP2)
+
+			c.httpClient = nil
+			c.httpTransport = nil
+			c.protocolTransport = nil
 
-			assert.Equal(t, tc.want.client, client)
+			assert.Equal(t, tc.want.client, c)
 
 		})
 	}
 }
 
+func TestNewClient_Isolation(t *testing.T) {
+	defaultTransport := http.DefaultTransport.(*http.Transport)
+	maxIdleConns := defaultTransport.MaxIdleConns
+	maxConnsPerHost := defaultTransport.MaxConnsPerHost
+	idleConnTimeout := defaultTransport.IdleConnTimeout
+
+	first := NewClient(
+		WithMaxIdleConnections(1),
+		WithMaxConnectionsPerHost(2),
+		WithIdleConnectionTimeout(time.Second),
+	).(*client)
+
+	second := NewClient(
+		WithMaxIdleConnections(10),
+		WithMaxConnectionsPerHost(20),
+		WithIdleConnectionTimeout(time.Minute),
+	).(*client)
+
+	firstTransport := first.httpTransport
+	secondTransport := second.httpTransport
+
+	assert.NotSame(t, firstTransport, secondTransport)
+
+	assert.Equal(t, 1, firstTransport.MaxIdleConns)
+	assert.Equal(t, 2, firstTransport.MaxConnsPerHost)
+	assert.Equal(t, time.Second, firstTransport.IdleConnTimeout)
+
+	assert.Equal(t, 10, secondTransport.MaxIdleConns)
+	assert.Equal(t, 20, secondTransport.MaxConnsPerHost)
+	assert.Equal(t, time.Minute, secondTransport.IdleConnTimeout)
+
+	assert.Equal(t, maxIdleConns, defaultTransport.MaxIdleConns)
+	assert.Equal(t, maxConnsPerHost, defaultTransport.MaxConnsPerHost)
+	assert.Equal(t, idleConnTimeout, defaultTransport.IdleConnTimeout)
+
+}
+
 func TestClient_Request(t *testing.T) {
 	type want struct {
 		req Request
@@ -351,13 +400,78 @@ func TestWithForceAttemptHTTP2(t *testing.T) {
 
 }
 
+func TestWithTransport(t *testing.T) {
+	transport := RoundTripper(
+		func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK}, nil
+		},
+	)
+
+	c := NewClient(WithTransport(transport)).(*client)
+
+	res, err := c.httpClient.Transport.RoundTrip(&http.Request{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	type args struct {
+		httpClient *http.Client
+	}
+
+	type test struct {
+		name string
+		args args
+	}
+
+	tests := []test{
+		{
+			name: "Without transport",
+			args: args{
+				httpClient: &http.Client{
+					Timeout: time.Second,
+				},
+			},
+		},
+		{
+			name: "With transport",
+			args: args{
+				httpClient: &http.Client{
+					Timeout:   time.Second,
+					Transport: &http.Transport{},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := tc.args.httpClient.Transport
+
+			c := NewClient(
+				WithHTTPClient(tc.args.httpClient),
+				WithInterceptors(Retry()),
+			).(*client)
+
+			assert.NotSame(t, tc.args.httpClient, c.httpClient)
+			assert.Equal(t, time.Second, c.httpClient.Timeout)
+			assert.NotNil(t, c.httpClient.Transport)
+			assert.Equal(t, transport, tc.args.httpClient.Transport)
+
+		})
+	}
+
+}
+
 func TestWithInterceptors(t *testing.T) {
 	type args struct {
 		interceptors []Interceptor
 	}
 
 	type want struct {
-		roundTripper http.RoundTripper
+		order []string
 	}
 
 	type test struct {
@@ -366,43 +480,55 @@ func TestWithInterceptors(t *testing.T) {
 		want want
 	}
 
+	var order []string
+
+	interceptor := func(name string) Interceptor {
+		return func(tripper http.RoundTripper) http.RoundTripper {
+			return RoundTripper(
+				func(req *http.Request) (*http.Response, error) {
+					order = append(order, name)
+					return tripper.RoundTrip(req)
+				},
+			)
+		}
+	}
+
 	tests := []test{
 		{
 			name: "WithInterceptors",
 			args: args{
 				interceptors: []Interceptor{
-					func(tripper http.RoundTripper) http.RoundTripper {
-						return RoundTripper(
-							func(req *http.Request) (*http.Response, error) {
-								return tripper.RoundTrip(req)
-							},
-						)
-					},
+					interceptor("first"),
+					interceptor("second"),
 				},
 			},
 			want: want{
-				roundTripper: func(tripper http.RoundTripper) http.RoundTripper {
-					return RoundTripper(
-						func(req *http.Request) (*http.Response, error) {
-							return tripper.RoundTrip(req)
-						},
-					)
-				}(http.DefaultTransport),
+				order: []string{"first", "second"},
 			},
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			c := &client{
-				httpClient: &http.Client{
-					Transport: nil,
-				},
-			}
+			order = nil
+
+			c := NewClient(
+				WithTransport(
+					RoundTripper(
+						func(req *http.Request) (*http.Response, error) {
+							return &http.Response{StatusCode: http.StatusOK}, nil
+						},
+					),
+				),
+				WithInterceptors(tc.args.interceptors...),
+			).(*client)
 
-			WithInterceptors(tc.args.interceptors...)(c)
+			assert.Len(t, c.interceptors, len(tc.args.interceptors))
 
-			assert.NotNil(t, c.httpClient.Transport)
+			_, err := c.httpClient.Transport.RoundTrip(&http.Request{})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want.order, order)
 
 		})
 	}
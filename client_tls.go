@@ -0,0 +1,174 @@
+package request
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrCertificatePinMismatch = errors.New("no pinned public key found in certificate chain")
+)
+
+const certificatePinPrefix = "sha256/"
+
+// tlsConfig builds tls.Config for client transport from TLS options.
+// Returns nil if none of TLS options is provided.
+func (c *client) tlsConfig() *tls.Config {
+	if c.tlsClientConfig == nil &&
+		c.rootCAs == nil &&
+		c.minTLSVersion == 0 &&
+		c.serverName == "" &&
+		len(c.cipherSuites) == 0 &&
+		len(c.certificatePins) == 0 &&
+		c.clientCertificate == nil {
+		return nil
+	}
+
+	config := &tls.Config{}
+	if c.tlsClientConfig != nil {
+		config = c.tlsClientConfig.Clone()
+	}
+
+	if c.rootCAs != nil {
+		config.RootCAs = c.rootCAs
+	}
+
+	if c.minTLSVersion != 0 {
+		config.MinVersion = c.minTLSVersion
+	}
+
+	if c.serverName != "" {
+		config.ServerName = c.serverName
+	}
+
+	if len(c.cipherSuites) != 0 {
+		config.CipherSuites = c.cipherSuites
+	}
+
+	if c.clientCertificate != nil {
+		config.GetClientCertificate = c.clientCertificate.GetClientCertificate
+	}
+
+	if len(c.certificatePins) != 0 {
+		config.VerifyConnection = verifyCertificatePins(
+			config.VerifyConnection,
+			c.certificatePins,
+			config.InsecureSkipVerify,
+		)
+	}
+
+	return config
+
+}
+
+// verifyCertificatePins rejects handshake when none of verified chains
+// contains pinned public key. Peer certificates sent by server are not
+// verified, so any certificate could be appended to them. If
+// insecureSkipVerify is set, there are no verified chains and only leaf
+// certificate is checked, as handshake proves server owns its key.
+func verifyCertificatePins(
+	next func(tls.ConnectionState) error,
+	pins []string,
+	insecureSkipVerify bool,
+) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if next != nil {
+			if err := next(cs); err != nil {
+				return err
+			}
+		}
+
+		chains := cs.VerifiedChains
+		if insecureSkipVerify && len(cs.PeerCertificates) != 0 {
+			chains = [][]*x509.Certificate{cs.PeerCertificates[:1]}
+		}
+
+		for _, chain := range chains {
+			for _, cert := range chain {
+				if slices.Contains(pins, CertificatePin(cert)) {
+					return nil
+				}
+			}
+		}
+
+		return ErrCertificatePinMismatch
+
+	}
+
+}
+
+// CertificatePin returns base64 encoded SHA-256 hash
+// of certificate Subject Public Key Info.
+func CertificatePin(cert *x509.Certificate) string {
+	hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+
+	return base64.StdEncoding.EncodeToString(hash[:])
+
+}
+
+// WithTLSConfig sets base TLS configuration for client transport.
+// The given config is cloned. Other TLS options take precedence over it.
+func WithTLSConfig(config *tls.Config) func(*client) {
+	return func(c *client) {
+		c.tlsClientConfig = config
+	}
+
+}
+
+// WithRootCAs sets certificate authorities used to verify server certificates.
+// If not provided, host root CA set is used.
+func WithRootCAs(rootCAs *x509.CertPool) func(*client) {
+	return func(c *client) {
+		c.rootCAs = rootCAs
+	}
+
+}
+
+// WithMinTLSVersion sets minimum TLS version, e.g. tls.VersionTLS12.
+func WithMinTLSVersion(version uint16) func(*client) {
+	return func(c *client) {
+		c.minTLSVersion = version
+	}
+
+}
+
+// WithServerName sets server name used to verify hostname
+// on returned certificates and sent in SNI.
+func WithServerName(serverName string) func(*client) {
+	return func(c *client) {
+		c.serverName = serverName
+	}
+
+}
+
+// WithCipherSuites sets enabled TLS 1.0–1.2 cipher suites.
+// TLS 1.3 cipher suites are not configurable.
+func WithCipherSuites(cipherSuites ...uint16) func(*client) {
+	return func(c *client) {
+		c.cipherSuites = cipherSuites
+	}
+
+}
+
+// WithCertificatePins enables SPKI pinning. Each pin is base64 encoded
+// SHA-256 hash of certificate Subject Public Key Info, optionally
+// prefixed with "sha256/". Handshake fails with ErrCertificatePinMismatch
+// when no pinned key appears in verified server certificate chain.
+// If InsecureSkipVerify is set by WithTLSConfig, there is no verified
+// chain and only server leaf certificate key is checked against pins.
+func WithCertificatePins(pins ...string) func(*client) {
+	return func(c *client) {
+		for _, pin := range pins {
+			c.certificatePins = append(
+				c.certificatePins,
+				strings.TrimPrefix(pin, certificatePinPrefix),
+			)
+		}
+	}
+
+}
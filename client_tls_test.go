@@ -0,0 +1,264 @@
+package request
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			},
+		),
+	)
+	defer server.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+
+	type args struct {
+		options []func(*client)
+	}
+
+	type want struct {
+		err error
+	}
+
+	type test struct {
+		name string
+		args args
+		want want
+	}
+
+	tests := []test{
+		{
+			name: "Unknown authority",
+			args: args{},
+			want: want{
+				err: x509.UnknownAuthorityError{},
+			},
+		},
+		{
+			name: "WithRootCAs",
+			args: args{
+				options: []func(*client){
+					WithRootCAs(rootCAs),
+				},
+			},
+		},
+		{
+			name: "WithTLSConfig",
+			args: args{
+				options: []func(*client){
+					WithTLSConfig(&tls.Config{RootCAs: rootCAs}),
+				},
+			},
+		},
+		{
+			name: "WithMinTLSVersion",
+			args: args{
+				options: []func(*client){
+					WithRootCAs(rootCAs),
+					WithMinTLSVersion(tls.VersionTLS12),
+				},
+			},
+		},
+		{
+			name: "WithServerName mismatch",
+			args: args{
+				options: []func(*client){
+					WithRootCAs(rootCAs),
+					WithServerName("unknown.local"),
+				},
+			},
+			want: want{
+				err: x509.HostnameError{},
+			},
+		},
+		{
+			name: "WithCertificatePins match",
+			args: args{
+				options: []func(*client){
+					WithRootCAs(rootCAs),
+					WithCertificatePins(
+						"sha256/" + CertificatePin(server.Certificate()),
+					),
+				},
+			},
+		},
+		{
+			name: "WithCertificatePins mismatch",
+			args: args{
+				options: []func(*client){
+					WithRootCAs(rootCAs),
+					WithCertificatePins("AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="),
+				},
+			},
+			want: want{
+				err: ErrCertificatePinMismatch,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient(tc.args.options...)
+
+			res, err := c.Request().Get(context.Background(), server.URL)
+
+			switch want := tc.want.err.(type) {
+			case nil:
+				assert.NoError(t, err)
+				assert.Equal(t, http.StatusOK, res.StatusCode)
+			case x509.UnknownAuthorityError:
+				assert.ErrorAs(t, err, &want)
+			case x509.HostnameError:
+				assert.ErrorAs(t, err, &want)
+			default:
+				assert.True(t, errors.Is(err, want))
+			}
+
+		})
+	}
+
+}
+
+func TestClient_TLS_CertificatePinsVerifiedChain(t *testing.T) {
+	ca := newTestCertificateAuthority(t)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "server"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+
+	leaf, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+
+	leafCert, err := x509.ParseCertificate(leaf)
+	require.NoError(t, err)
+
+	// Pinned certificate is public, so server can append it to chain
+	// it sends, though it is not part of verified chain.
+	pinned := newTestCertificateAuthority(t).cert
+
+	server := httptest.NewUnstartedServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			},
+		),
+	)
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{
+			{
+				Certificate: [][]byte{leaf, pinned.Raw},
+				PrivateKey:  key,
+			},
+		},
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(ca.cert)
+
+	type args struct {
+		options []func(*client)
+	}
+
+	type want struct {
+		err error
+	}
+
+	type test struct {
+		name string
+		args args
+		want want
+	}
+
+	tests := []test{
+		{
+			name: "Appended certificate is not pinned",
+			args: args{
+				options: []func(*client){
+					WithRootCAs(rootCAs),
+					WithCertificatePins(CertificatePin(pinned)),
+				},
+			},
+			want: want{
+				err: ErrCertificatePinMismatch,
+			},
+		},
+		{
+			name: "Verified chain certificate is pinned",
+			args: args{
+				options: []func(*client){
+					WithRootCAs(rootCAs),
+					WithCertificatePins(CertificatePin(ca.cert)),
+				},
+			},
+		},
+		{
+			name: "Appended certificate is not pinned without verification",
+			args: args{
+				options: []func(*client){
+					WithTLSConfig(&tls.Config{InsecureSkipVerify: true}),
+					WithCertificatePins(CertificatePin(pinned)),
+				},
+			},
+			want: want{
+				err: ErrCertificatePinMismatch,
+			},
+		},
+		{
+			name: "Leaf certificate is pinned without verification",
+			args: args{
+				options: []func(*client){
+					WithTLSConfig(&tls.Config{InsecureSkipVerify: true}),
+					WithCertificatePins(CertificatePin(leafCert)),
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient(tc.args.options...)
+
+			res, err := c.Request().Get(context.Background(), server.URL)
+			if tc.want.err != nil {
+				assert.ErrorIs(t, err, tc.want.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		})
+	}
+
+}
@@ -0,0 +1,418 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultAdaptiveInitialLimit = 20
+	DefaultAdaptiveMinLimit     = 1
+	DefaultAdaptiveMaxLimit     = 200
+	DefaultAdaptiveBackoffRatio = 0.9
+	DefaultGradientSmoothing    = 0.2
+	DefaultGradientTolerance    = 1.5
+	DefaultGradientLongWindow   = 600
+)
+
+var (
+	ErrLimitExceeded = errors.New("concurrency limit exceeded")
+)
+
+// LimitSample is result of single request used to adjust concurrency limit.
+type LimitSample struct {
+	// RTT is time until Response HEADER is received.
+	RTT time.Duration
+
+	// InFlight is number of requests in flight when request is finished,
+	// including the request.
+	InFlight int
+
+	// Dropped reports request failed due to overload.
+	Dropped bool
+}
+
+// LimitAlgorithm adjusts concurrency limit of single key from samples.
+type LimitAlgorithm interface {
+	// Limit returns current concurrency limit.
+	Limit() int
+
+	// Update adjusts limit from request sample and returns new limit.
+	Update(sample LimitSample) int
+}
+
+// AdaptiveLimitOptions configures AdaptiveLimit interceptor.
+// Zero values are replaced by defaults.
+type AdaptiveLimitOptions struct {
+	// Algorithm returns limit algorithm of new key, AIMD by default.
+	Algorithm func() LimitAlgorithm
+
+	// Key returns limit key of request, request host by default.
+	Key func(req *http.Request) string
+
+	// IsDropped decides whether request failed due to overload, by default
+	// errors, except context cancellation, 429 and 5xx status codes are.
+	IsDropped func(res *http.Response, err error) bool
+
+	// OnLimitChange is called on every limit change.
+	OnLimitChange func(key string, limit int)
+
+	// Now returns current time used to measure RTT, time.Now by default.
+	Now func() time.Time
+}
+
+// withDefaults returns options with zero values replaced by defaults.
+func (o AdaptiveLimitOptions) withDefaults() AdaptiveLimitOptions {
+	if o.Algorithm == nil {
+		o.Algorithm = AIMD(AIMDOptions{})
+	}
+
+	if o.Key == nil {
+		o.Key = KeyByHost
+	}
+
+	if o.IsDropped == nil {
+		o.IsDropped = isDropped
+	}
+
+	if o.Now == nil {
+		o.Now = time.Now
+	}
+
+	return o
+
+}
+
+// adaptiveKey is limit state of single key.
+type adaptiveKey struct {
+	algorithm LimitAlgorithm
+	inFlight  int
+	used      time.Time
+}
+
+type adaptiveLimit struct {
+	options AdaptiveLimitOptions
+
+	mu      sync.Mutex
+	keys    map[string]*adaptiveKey
+	sweeper keySweeper
+}
+
+// AdaptiveLimit interceptor limits number of requests in flight per host,
+// or other key, adjusting the limit from observed RTT and overload errors
+// by limit algorithm, see AIMD and Gradient. Request exceeding the limit
+// fails fast with ErrLimitExceeded. Request is in flight until its
+// response body is closed. Limit of key without requests for a minute
+// is evicted, and it starts from initial limit again.
+func AdaptiveLimit(options AdaptiveLimitOptions) Interceptor {
+	limit := &adaptiveLimit{
+		options: options.withDefaults(),
+		keys:    make(map[string]*adaptiveKey),
+	}
+
+	return func(tripper http.RoundTripper) http.RoundTripper {
+		return RoundTripper(
+			func(req *http.Request) (*http.Response, error) {
+				key := limit.options.Key(req)
+
+				if err := limit.acquire(key); err != nil {
+					closeRequestBody(req)
+
+					return nil, fmt.Errorf("%s %s: %w", req.Method, req.URL.Redacted(), err)
+				}
+
+				started := limit.options.Now()
+
+				res, err := tripper.RoundTrip(req)
+
+				limit.update(
+					key,
+					limit.options.Now().Sub(started),
+					limit.options.IsDropped(res, err),
+					errors.Is(err, context.Canceled),
+				)
+
+				release := sync.OnceFunc(func() { limit.release(key) })
+
+				if err != nil {
+					release()
+
+					return nil, err
+				}
+
+				res.Body = cancelOnClose(res.Body, release)
+
+				return res, nil
+
+			},
+		)
+	}
+
+}
+
+// acquire counts request in flight unless limit of key is reached.
+func (l *adaptiveLimit) acquire(key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.options.Now()
+
+	if l.sweeper.due(now) {
+		maps.DeleteFunc(
+			l.keys,
+			func(_ string, k *adaptiveKey) bool {
+				return k.inFlight == 0 && now.Sub(k.used) >= keySweepInterval
+			},
+		)
+	}
+
+	k, ok := l.keys[key]
+	if !ok {
+		k = &adaptiveKey{algorithm: l.options.Algorithm()}
+		l.keys[key] = k
+	}
+
+	k.used = now
+
+	if k.inFlight >= k.algorithm.Limit() {
+		return ErrLimitExceeded
+	}
+
+	k.inFlight++
+
+	return nil
+
+}
+
+// update adjusts limit of key from request sample,
+// canceled requests are ignored.
+func (l *adaptiveLimit) update(key string, rtt time.Duration, dropped bool, canceled bool) {
+	if canceled {
+		return
+	}
+
+	l.mu.Lock()
+
+	k := l.keys[key]
+	previous := k.algorithm.Limit()
+	limit := k.algorithm.Update(
+		LimitSample{
+			RTT:      rtt,
+			InFlight: k.inFlight,
+			Dropped:  dropped,
+		},
+	)
+
+	l.mu.Unlock()
+
+	if limit != previous && l.options.OnLimitChange != nil {
+		l.options.OnLimitChange(key, limit)
+	}
+
+}
+
+func (l *adaptiveLimit) release(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	k := l.keys[key]
+	k.inFlight--
+	k.used = l.options.Now()
+
+}
+
+// isDropped reports failures and 429 status code as overload.
+func isDropped(res *http.Response, err error) bool {
+	if isFailure(res, err) {
+		return true
+	}
+
+	return res != nil && res.StatusCode == http.StatusTooManyRequests
+
+}
+
+// AIMDOptions configures AIMD limit algorithm.
+// Zero values are replaced by defaults.
+type AIMDOptions struct {
+	InitialLimit int
+	MinLimit     int
+	MaxLimit     int
+
+	// BackoffRatio multiplies limit on dropped request.
+	BackoffRatio float64
+
+	// Timeout treats request with longer RTT as dropped, zero disables it.
+	Timeout time.Duration
+}
+
+// withDefaults returns options with zero values replaced by defaults.
+func (o AIMDOptions) withDefaults() AIMDOptions {
+	o.InitialLimit, o.MinLimit, o.MaxLimit = limitDefaults(o.InitialLimit, o.MinLimit, o.MaxLimit)
+
+	if o.BackoffRatio <= 0 || o.BackoffRatio >= 1 {
+		o.BackoffRatio = DefaultAdaptiveBackoffRatio
+	}
+
+	return o
+
+}
+
+type aimdLimit struct {
+	options AIMDOptions
+	limit   int
+}
+
+// AIMD returns additive increase, multiplicative decrease limit algorithm.
+// Limit is increased by one on successful request while at least half
+// of it is used, and multiplied by backoff ratio on dropped request.
+func AIMD(options AIMDOptions) func() LimitAlgorithm {
+	options = options.withDefaults()
+
+	return func() LimitAlgorithm {
+		return &aimdLimit{
+			options: options,
+			limit:   options.InitialLimit,
+		}
+	}
+
+}
+
+func (a *aimdLimit) Limit() int {
+	return a.limit
+}
+
+func (a *aimdLimit) Update(sample LimitSample) int {
+	switch {
+	case sample.Dropped || (a.options.Timeout > 0 && sample.RTT > a.options.Timeout):
+		a.limit = int(float64(a.limit) * a.options.BackoffRatio)
+	case sample.InFlight*2 >= a.limit:
+		a.limit++
+	}
+
+	a.limit = min(max(a.limit, a.options.MinLimit), a.options.MaxLimit)
+
+	return a.limit
+
+}
+
+// GradientOptions configures Gradient limit algorithm.
+// Zero values are replaced by defaults.
+type GradientOptions struct {
+	InitialLimit int
+	MinLimit     int
+	MaxLimit     int
+
+	// BackoffRatio multiplies limit on dropped request.
+	BackoffRatio float64
+
+	// Smoothing is weight of new limit estimate.
+	Smoothing float64
+
+	// Tolerance is ratio of RTT to long term RTT tolerated
+	// before limit is decreased.
+	Tolerance float64
+
+	// LongWindow is number of samples long term RTT is averaged over.
+	LongWindow int
+}
+
+// withDefaults returns options with zero values replaced by defaults.
+func (o GradientOptions) withDefaults() GradientOptions {
+	o.InitialLimit, o.MinLimit, o.MaxLimit = limitDefaults(o.InitialLimit, o.MinLimit, o.MaxLimit)
+
+	if o.BackoffRatio <= 0 || o.BackoffRatio >= 1 {
+		o.BackoffRatio = DefaultAdaptiveBackoffRatio
+	}
+
+	if o.Smoothing <= 0 || o.Smoothing > 1 {
+		o.Smoothing = DefaultGradientSmoothing
+	}
+
+	if o.Tolerance < 1 {
+		o.Tolerance = DefaultGradientTolerance
+	}
+
+	if o.LongWindow <= 0 {
+		o.LongWindow = DefaultGradientLongWindow
+	}
+
+	return o
+
+}
+
+type gradientLimit struct {
+	options GradientOptions
+	limit   float64
+	longRTT float64
+}
+
+// Gradient returns Vegas like limit algorithm comparing request RTT with
+// long term average RTT. Growing RTT means requests are queued by server,
+// so limit is scaled down by gradient of the two, otherwise limit grows
+// by square root of itself, which is allowed queue size.
+func Gradient(options GradientOptions) func() LimitAlgorithm {
+	options = options.withDefaults()
+
+	return func() LimitAlgorithm {
+		return &gradientLimit{
+			options: options,
+			limit:   float64(options.InitialLimit),
+		}
+	}
+
+}
+
+func (g *gradientLimit) Limit() int {
+	return int(g.limit)
+}
+
+func (g *gradientLimit) Update(sample LimitSample) int {
+	rtt := float64(sample.RTT)
+
+	if g.longRTT == 0 {
+		g.longRTT = rtt
+	} else {
+		g.longRTT += (rtt - g.longRTT) / float64(g.options.LongWindow)
+	}
+
+	switch {
+	case sample.Dropped:
+		g.limit *= g.options.BackoffRatio
+	case rtt <= 0 || float64(sample.InFlight)*2 < g.limit:
+		// Limit is not adjusted while most of it is unused.
+	default:
+		gradient := min(max(g.options.Tolerance*g.longRTT/rtt, 0.5), 1)
+		estimate := g.limit*gradient + math.Sqrt(g.limit)
+
+		g.limit = g.limit*(1-g.options.Smoothing) + estimate*g.options.Smoothing
+	}
+
+	g.limit = min(max(g.limit, float64(g.options.MinLimit)), float64(g.options.MaxLimit))
+
+	return g.Limit()
+
+}
+
+// limitDefaults replaces zero limits by defaults keeping them in order.
+func limitDefaults(initial int, minimum int, maximum int) (int, int, int) {
+	if minimum <= 0 {
+		minimum = DefaultAdaptiveMinLimit
+	}
+
+	if maximum <= 0 {
+		maximum = max(DefaultAdaptiveMaxLimit, minimum)
+	}
+
+	if initial <= 0 {
+		initial = DefaultAdaptiveInitialLimit
+	}
+
+	return min(max(initial, minimum), maximum), minimum, maximum
+
+}
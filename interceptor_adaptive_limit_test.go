@@ -0,0 +1,233 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAIMD(t *testing.T) {
+	type args struct {
+		options AIMDOptions
+		samples []LimitSample
+	}
+
+	type want struct {
+		limits []int
+	}
+
+	type test struct {
+		name string
+		args args
+		want want
+	}
+
+	tests := []test{
+		{
+			name: "Increases while used",
+			args: args{
+				options: AIMDOptions{InitialLimit: 4, MaxLimit: 6},
+				samples: []LimitSample{
+					{InFlight: 2},
+					{InFlight: 3},
+					{InFlight: 1},
+					{InFlight: 4},
+					{InFlight: 6},
+				},
+			},
+			want: want{
+				limits: []int{5, 6, 6, 6, 6},
+			},
+		},
+		{
+			name: "Decreases on drops and timeouts",
+			args: args{
+				options: AIMDOptions{InitialLimit: 10, MinLimit: 4, BackoffRatio: 0.5, Timeout: time.Second},
+				samples: []LimitSample{
+					{InFlight: 10, Dropped: true},
+					{InFlight: 5, RTT: 2 * time.Second},
+					{InFlight: 2, Dropped: true},
+					{InFlight: 2},
+				},
+			},
+			want: want{
+				limits: []int{5, 4, 4, 5},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			algorithm := AIMD(tc.args.options)()
+
+			var limits []int
+			for _, sample := range tc.args.samples {
+				limits = append(limits, algorithm.Update(sample))
+			}
+
+			assert.Equal(t, tc.want.limits, limits)
+			assert.Equal(t, limits[len(limits)-1], algorithm.Limit())
+
+		})
+	}
+
+}
+
+func TestGradient(t *testing.T) {
+	algorithm := Gradient(GradientOptions{InitialLimit: 16, LongWindow: 10})()
+
+	for i := 0; i < 5; i++ {
+		algorithm.Update(LimitSample{RTT: 10 * time.Millisecond, InFlight: algorithm.Limit()})
+	}
+
+	grown := algorithm.Limit()
+	assert.Greater(t, grown, 16)
+
+	assert.Equal(t, grown, algorithm.Update(LimitSample{RTT: 10 * time.Millisecond, InFlight: 1}))
+
+	for i := 0; i < 5; i++ {
+		algorithm.Update(LimitSample{RTT: 100 * time.Millisecond, InFlight: algorithm.Limit()})
+	}
+
+	assert.Less(t, algorithm.Limit(), grown)
+
+	limit := algorithm.Limit()
+	assert.Equal(t, int(float64(limit)*DefaultAdaptiveBackoffRatio), algorithm.Update(LimitSample{Dropped: true}))
+
+	for i := 0; i < 100; i++ {
+		algorithm.Update(LimitSample{Dropped: true})
+	}
+
+	assert.Equal(t, DefaultAdaptiveMinLimit, algorithm.Limit())
+
+}
+
+func TestAdaptiveLimit(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	type change struct {
+		key   string
+		limit int
+	}
+
+	var (
+		changes []change
+		status  = http.StatusOK
+		failure error
+	)
+
+	c := NewClient(
+		WithTransport(
+			RoundTripper(
+				func(req *http.Request) (*http.Response, error) {
+					now = now.Add(10 * time.Millisecond)
+
+					if failure != nil {
+						return nil, failure
+					}
+
+					return &http.Response{StatusCode: status, Body: http.NoBody}, nil
+				},
+			),
+		),
+		WithInterceptors(
+			AdaptiveLimit(
+				AdaptiveLimitOptions{
+					Algorithm: AIMD(AIMDOptions{InitialLimit: 2, MaxLimit: 3, BackoffRatio: 0.5}),
+					OnLimitChange: func(key string, limit int) {
+						changes = append(changes, change{key: key, limit: limit})
+					},
+					Now: func() time.Time { return now },
+				},
+			),
+		),
+	)
+
+	get := func(host string) (*Response, error) {
+		return c.Request().Get(context.Background(), "http://"+host)
+	}
+
+	var responses []*Response
+	for i := 0; i < 3; i++ {
+		res, err := get("a.example.com")
+		require.NoError(t, err)
+
+		responses = append(responses, res)
+	}
+
+	_, err := get("a.example.com")
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+
+	res, err := get("b.example.com")
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	for _, res := range responses {
+		require.NoError(t, res.Body.Close())
+	}
+
+	status = http.StatusTooManyRequests
+
+	res, err = get("a.example.com")
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	failure = context.Canceled
+
+	_, err = get("a.example.com")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	failure = errors.New("connection refused")
+
+	_, err = get("a.example.com")
+	assert.Error(t, err)
+
+	status, failure = http.StatusOK, nil
+
+	res, err = get("a.example.com")
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	assert.Equal(
+		t,
+		[]change{
+			{key: "a.example.com", limit: 3},
+			{key: "b.example.com", limit: 3},
+			{key: "a.example.com", limit: 1},
+			{key: "a.example.com", limit: 2},
+		},
+		changes,
+	)
+
+}
+
+func TestAdaptiveLimit_Evict(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	limit := &adaptiveLimit{
+		options: AdaptiveLimitOptions{
+			Now: func() time.Time { return now },
+		}.withDefaults(),
+		keys: make(map[string]*adaptiveKey),
+	}
+
+	for _, key := range []string{"idle", "in-flight", "recent"} {
+		require.NoError(t, limit.acquire(key))
+	}
+
+	limit.release("idle")
+
+	now = now.Add(keySweepInterval / 2)
+	limit.release("recent")
+
+	now = now.Add(keySweepInterval / 2)
+	require.NoError(t, limit.acquire("other"))
+
+	assert.Len(t, limit.keys, 3)
+	assert.NotContains(t, limit.keys, "idle")
+
+}
@@ -0,0 +1,236 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+const DefaultBulkheadMaxConcurrent = 10
+
+var (
+	ErrBulkheadFull    = errors.New("bulkhead is full")
+	ErrBulkheadTimeout = errors.New("bulkhead queue timeout exceeded")
+)
+
+// BulkheadOptions configures Bulkhead. Zero values are replaced by defaults.
+type BulkheadOptions struct {
+	// MaxConcurrent is number of requests in flight at once.
+	MaxConcurrent int
+
+	// MaxQueue is number of requests waiting for their turn,
+	// zero means requests are rejected once MaxConcurrent is reached.
+	MaxQueue int
+
+	// QueueTimeout limits time request waits in queue,
+	// zero means it waits until request context is done.
+	QueueTimeout time.Duration
+
+	// Key returns compartment key of request, nil means
+	// single global compartment. See KeyByHost.
+	Key func(req *http.Request) string
+}
+
+// withDefaults returns options with zero values replaced by defaults.
+func (o BulkheadOptions) withDefaults() BulkheadOptions {
+	if o.MaxConcurrent <= 0 {
+		o.MaxConcurrent = DefaultBulkheadMaxConcurrent
+	}
+
+	if o.MaxQueue < 0 {
+		o.MaxQueue = 0
+	}
+
+	if o.Key == nil {
+		o.Key = func(*http.Request) string { return "" }
+	}
+
+	return o
+
+}
+
+// compartment counts requests of single bulkhead key. Waiting requests
+// are queued in order and get slot of finished request.
+type compartment struct {
+	inFlight int
+	queue    []chan struct{}
+}
+
+// Bulkhead limits number of requests in flight, global or per key,
+// with bounded wait queue. Request is in flight until its response
+// body is closed. Compartment is evicted once it has no requests.
+type Bulkhead struct {
+	options BulkheadOptions
+
+	mu           sync.Mutex
+	compartments map[string]*compartment
+}
+
+// NewBulkhead returns Bulkhead, its Interceptor method is used
+// as client interceptor.
+func NewBulkhead(options BulkheadOptions) *Bulkhead {
+	return &Bulkhead{
+		options:      options.withDefaults(),
+		compartments: make(map[string]*compartment),
+	}
+
+}
+
+// Interceptor implements Interceptor. Request exceeding the limit waits
+// in queue, and is rejected with ErrBulkheadFull if the queue is full
+// or with ErrBulkheadTimeout if it waits longer than queue timeout.
+func (b *Bulkhead) Interceptor(tripper http.RoundTripper) http.RoundTripper {
+	return RoundTripper(
+		func(req *http.Request) (*http.Response, error) {
+			release, err := b.acquire(req.Context(), b.options.Key(req))
+			if err != nil {
+				closeRequestBody(req)
+
+				return nil, fmt.Errorf("%s %s: %w", req.Method, req.URL.Redacted(), err)
+			}
+
+			res, err := tripper.RoundTrip(req)
+			if err != nil {
+				release()
+
+				return nil, err
+			}
+
+			res.Body = cancelOnClose(res.Body, release)
+
+			return res, nil
+
+		},
+	)
+
+}
+
+// InFlight returns number of requests in flight with given key,
+// key of global bulkhead is empty.
+func (b *Bulkhead) InFlight(key string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c, ok := b.compartments[key]; ok {
+		return c.inFlight
+	}
+
+	return 0
+
+}
+
+// Queued returns number of requests waiting in queue with given key,
+// key of global bulkhead is empty.
+func (b *Bulkhead) Queued(key string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c, ok := b.compartments[key]; ok {
+		return len(c.queue)
+	}
+
+	return 0
+
+}
+
+// acquire takes slot of compartment, waiting in queue if needed,
+// and returns function releasing it.
+func (b *Bulkhead) acquire(ctx context.Context, key string) (func(), error) {
+	b.mu.Lock()
+
+	c, ok := b.compartments[key]
+	if !ok {
+		c = &compartment{}
+		b.compartments[key] = c
+	}
+
+	if c.inFlight < b.options.MaxConcurrent {
+		c.inFlight++
+		b.mu.Unlock()
+
+		return b.releaser(key, c), nil
+	}
+
+	if len(c.queue) >= b.options.MaxQueue {
+		b.mu.Unlock()
+
+		return nil, ErrBulkheadFull
+	}
+
+	turn := make(chan struct{})
+	c.queue = append(c.queue, turn)
+
+	b.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if b.options.QueueTimeout > 0 {
+		timer := time.NewTimer(b.options.QueueTimeout)
+		defer timer.Stop()
+
+		timeout = timer.C
+	}
+
+	var err error
+
+	select {
+	case <-turn:
+		return b.releaser(key, c), nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-timeout:
+		err = ErrBulkheadTimeout
+	}
+
+	b.mu.Lock()
+
+	if i := slices.Index(c.queue, turn); i >= 0 {
+		c.queue = slices.Delete(c.queue, i, i+1)
+		b.mu.Unlock()
+
+		return nil, err
+	}
+
+	b.mu.Unlock()
+
+	// Slot is handed over while giving up, so pass it further.
+	b.releaser(key, c)()
+
+	return nil, err
+
+}
+
+// releaser returns function handing slot over to the first queued
+// request or freeing it, the function is safe to call repeatedly.
+// Compartment without requests is evicted, as it is the same as new one.
+func (b *Bulkhead) releaser(key string, c *compartment) func() {
+	var once sync.Once
+
+	return func() {
+		once.Do(
+			func() {
+				b.mu.Lock()
+				defer b.mu.Unlock()
+
+				if len(c.queue) == 0 {
+					c.inFlight--
+
+					if c.inFlight == 0 {
+						delete(b.compartments, key)
+					}
+
+					return
+				}
+
+				turn := c.queue[0]
+				c.queue = c.queue[1:]
+
+				close(turn)
+			},
+		)
+	}
+
+}
@@ -0,0 +1,193 @@
+package request
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// testBlockingClient returns client with bulkhead interceptor,
+// its requests wait for unblock channel before responding.
+func testBlockingClient(bulkhead *Bulkhead) (Client, chan struct{}) {
+	unblock := make(chan struct{})
+
+	c := NewClient(
+		WithTransport(
+			RoundTripper(
+				func(req *http.Request) (*http.Response, error) {
+					select {
+					case <-unblock:
+					case <-req.Context().Done():
+						return nil, req.Context().Err()
+					}
+
+					return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+				},
+			),
+		),
+		WithInterceptors(bulkhead.Interceptor),
+	)
+
+	return c, unblock
+
+}
+
+func TestBulkhead(t *testing.T) {
+	bulkhead := NewBulkhead(BulkheadOptions{MaxConcurrent: 1, MaxQueue: 1})
+	c, unblock := testBlockingClient(bulkhead)
+
+	results := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			res, err := c.Request().Get(context.Background(), "http://localhost:8080")
+			if err == nil {
+				err = res.Body.Close()
+			}
+
+			results <- err
+		}()
+	}
+
+	require.Eventually(
+		t,
+		func() bool { return bulkhead.InFlight("") == 1 && bulkhead.Queued("") == 1 },
+		time.Second,
+		time.Millisecond,
+	)
+
+	_, err := c.Request().Get(context.Background(), "http://localhost:8080")
+	assert.ErrorIs(t, err, ErrBulkheadFull)
+
+	close(unblock)
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, <-results)
+	}
+
+	assert.Zero(t, bulkhead.InFlight(""))
+	assert.Zero(t, bulkhead.Queued(""))
+	assert.Empty(t, bulkhead.compartments)
+
+}
+
+func TestBulkhead_InFlightUntilBodyClose(t *testing.T) {
+	bulkhead := NewBulkhead(BulkheadOptions{MaxConcurrent: 1})
+	c, unblock := testBlockingClient(bulkhead)
+	close(unblock)
+
+	res, err := c.Request().Get(context.Background(), "http://localhost:8080")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, bulkhead.InFlight(""))
+
+	_, err = c.Request().Get(context.Background(), "http://localhost:8080")
+	assert.ErrorIs(t, err, ErrBulkheadFull)
+
+	require.NoError(t, res.Body.Close())
+	require.NoError(t, res.Body.Close())
+
+	assert.Zero(t, bulkhead.InFlight(""))
+	assert.Empty(t, bulkhead.compartments)
+
+}
+
+func TestBulkhead_Queue(t *testing.T) {
+	type args struct {
+		options BulkheadOptions
+		ctx     func() (context.Context, context.CancelFunc)
+	}
+
+	type want struct {
+		err error
+	}
+
+	type test struct {
+		name string
+		args args
+		want want
+	}
+
+	tests := []test{
+		{
+			name: "Queue timeout",
+			args: args{
+				options: BulkheadOptions{MaxConcurrent: 1, MaxQueue: 1, QueueTimeout: 10 * time.Millisecond},
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.WithCancel(context.Background())
+				},
+			},
+			want: want{
+				err: ErrBulkheadTimeout,
+			},
+		},
+		{
+			name: "Context canceled in queue",
+			args: args{
+				options: BulkheadOptions{MaxConcurrent: 1, MaxQueue: 1},
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.WithTimeout(context.Background(), 10*time.Millisecond)
+				},
+			},
+			want: want{
+				err: context.DeadlineExceeded,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			bulkhead := NewBulkhead(tc.args.options)
+			c, unblock := testBlockingClient(bulkhead)
+
+			done := make(chan error)
+			go func() {
+				_, err := c.Request().Get(context.Background(), "http://localhost:8080")
+				done <- err
+			}()
+
+			require.Eventually(
+				t,
+				func() bool { return bulkhead.InFlight("") == 1 },
+				time.Second,
+				time.Millisecond,
+			)
+
+			ctx, cancel := tc.args.ctx()
+			defer cancel()
+
+			_, err := c.Request().Get(ctx, "http://localhost:8080")
+			assert.ErrorIs(t, err, tc.want.err)
+			assert.Zero(t, bulkhead.Queued(""))
+
+			close(unblock)
+			assert.NoError(t, <-done)
+
+		})
+	}
+
+}
+
+func TestBulkhead_KeyByHost(t *testing.T) {
+	bulkhead := NewBulkhead(BulkheadOptions{MaxConcurrent: 1, Key: KeyByHost})
+	c, unblock := testBlockingClient(bulkhead)
+	close(unblock)
+
+	first, err := c.Request().Get(context.Background(), "http://a.example.com")
+	require.NoError(t, err)
+
+	second, err := c.Request().Get(context.Background(), "http://b.example.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, bulkhead.InFlight("a.example.com"))
+	assert.Equal(t, 1, bulkhead.InFlight("b.example.com"))
+
+	_, err = c.Request().Get(context.Background(), "http://a.example.com")
+	assert.ErrorIs(t, err, ErrBulkheadFull)
+
+	require.NoError(t, first.Body.Close())
+	require.NoError(t, second.Body.Close())
+
+}
@@ -0,0 +1,399 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultCircuitBreakerConsecutiveFailures = 5
+	DefaultCircuitBreakerMinRequests         = 10
+	DefaultCircuitBreakerCoolDown            = 30 * time.Second
+	DefaultCircuitBreakerHalfOpenProbes      = 1
+)
+
+var (
+	ErrCircuitOpen = errors.New("circuit breaker is open")
+)
+
+// CircuitState is state of circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets requests through and counts failures.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails requests fast until cool-down is over.
+	CircuitOpen
+	// CircuitHalfOpen lets limited number of probe requests through.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+
+}
+
+// CircuitBreakerOptions configures CircuitBreaker interceptor.
+// Zero values are replaced by defaults.
+type CircuitBreakerOptions struct {
+	// Key returns circuit key of request, request host by default.
+	Key func(req *http.Request) string
+
+	// ConsecutiveFailures opens circuit after given number
+	// of failures in a row.
+	ConsecutiveFailures int
+
+	// FailureRatio opens circuit once ratio of failed requests
+	// within Interval reaches it, zero disables the threshold.
+	FailureRatio float64
+
+	// MinRequests is minimal number of requests within Interval
+	// FailureRatio is checked for.
+	MinRequests int
+
+	// Interval is period closed circuit counts requests for FailureRatio,
+	// zero means counts are kept until circuit is opened.
+	Interval time.Duration
+
+	// CoolDown is time open circuit fails fast before switching to half-open.
+	CoolDown time.Duration
+
+	// HalfOpenProbes is number of requests let through half-open circuit,
+	// circuit is closed once all of them succeed and opened on any failure.
+	HalfOpenProbes int
+
+	// IsFailure decides whether request result is failure, by default
+	// errors and 5xx status codes are. Requests canceled by caller
+	// are neither failures nor successes and are not recorded.
+	IsFailure func(res *http.Response, err error) bool
+
+	// OnStateChange is called on every circuit state change.
+	OnStateChange func(key string, from CircuitState, to CircuitState)
+
+	now func() time.Time
+}
+
+// withDefaults returns options with zero values replaced by defaults.
+func (o CircuitBreakerOptions) withDefaults() CircuitBreakerOptions {
+	if o.Key == nil {
+		o.Key = KeyByHost
+	}
+
+	if o.ConsecutiveFailures <= 0 {
+		o.ConsecutiveFailures = DefaultCircuitBreakerConsecutiveFailures
+	}
+
+	if o.MinRequests <= 0 {
+		o.MinRequests = DefaultCircuitBreakerMinRequests
+	}
+
+	if o.CoolDown <= 0 {
+		o.CoolDown = DefaultCircuitBreakerCoolDown
+	}
+
+	if o.HalfOpenProbes <= 0 {
+		o.HalfOpenProbes = DefaultCircuitBreakerHalfOpenProbes
+	}
+
+	if o.IsFailure == nil {
+		o.IsFailure = isFailure
+	}
+
+	if o.now == nil {
+		o.now = time.Now
+	}
+
+	return o
+
+}
+
+// circuit is state of single circuit breaker key. Generation changes
+// with every state change or counts reset, so results of requests
+// started before are ignored.
+type circuit struct {
+	state        CircuitState
+	generation   uint64
+	changedAt    time.Time
+	requests     int
+	failures     int
+	consecutive  int
+	probes       int
+	probeSuccess int
+
+	// inFlight is number of requests let through, it is kept across
+	// generations, so circuit is not evicted while they are in flight.
+	inFlight int
+}
+
+// circuitOutcome is request result recorded by circuit.
+type circuitOutcome int
+
+const (
+	circuitSuccess circuitOutcome = iota
+	circuitFailure
+	// circuitCanceled is neither success nor failure,
+	// as request was canceled by caller.
+	circuitCanceled
+)
+
+type circuitTransition struct {
+	key  string
+	from CircuitState
+	to   CircuitState
+}
+
+type circuitBreaker struct {
+	options CircuitBreakerOptions
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+	sweeper  keySweeper
+}
+
+// CircuitBreaker interceptor stops sending requests to failing host,
+// or other key, failing them fast with ErrCircuitOpen. Circuit is opened
+// on consecutive failures or failure ratio threshold, after cool-down it is
+// half-open and lets probe requests through to decide whether to close.
+// Closed circuits without failures are evicted every minute.
+func CircuitBreaker(options CircuitBreakerOptions) Interceptor {
+	breaker := &circuitBreaker{
+		options:  options.withDefaults(),
+		circuits: make(map[string]*circuit),
+	}
+
+	return func(tripper http.RoundTripper) http.RoundTripper {
+		return RoundTripper(
+			func(req *http.Request) (*http.Response, error) {
+				key := breaker.options.Key(req)
+
+				generation, err := breaker.before(key)
+				if err != nil {
+					closeRequestBody(req)
+
+					return nil, err
+				}
+
+				res, err := tripper.RoundTrip(req)
+
+				outcome := circuitSuccess
+
+				switch {
+				case errors.Is(err, context.Canceled):
+					outcome = circuitCanceled
+				case breaker.options.IsFailure(res, err):
+					outcome = circuitFailure
+				}
+
+				breaker.after(key, generation, outcome)
+
+				return res, err
+
+			},
+		)
+	}
+
+}
+
+// before checks whether request is let through circuit of given key
+// and returns circuit generation.
+func (b *circuitBreaker) before(key string) (uint64, error) {
+	b.mu.Lock()
+
+	if now := b.options.now(); b.sweeper.due(now) {
+		maps.DeleteFunc(
+			b.circuits,
+			func(_ string, c *circuit) bool {
+				return b.idle(c, now)
+			},
+		)
+	}
+
+	c, transition := b.circuit(key)
+	generation := c.generation
+
+	var err error
+
+	switch c.state {
+	case CircuitOpen:
+		err = fmt.Errorf("%w: %s", ErrCircuitOpen, key)
+	case CircuitHalfOpen:
+		if c.probes >= b.options.HalfOpenProbes {
+			err = fmt.Errorf("%w: %s: probes are exhausted", ErrCircuitOpen, key)
+		} else {
+			c.probes++
+		}
+	}
+
+	if err == nil {
+		c.inFlight++
+	}
+
+	b.mu.Unlock()
+
+	b.notify(transition)
+
+	return generation, err
+
+}
+
+// after records request outcome of circuit generation. Canceled
+// request is not recorded, only its half-open probe slot is released.
+func (b *circuitBreaker) after(key string, generation uint64, outcome circuitOutcome) {
+	b.mu.Lock()
+
+	c, transition := b.circuit(key)
+	c.inFlight--
+
+	failure := outcome == circuitFailure
+
+	if c.generation == generation {
+		switch {
+		case outcome == circuitCanceled:
+			if c.state == CircuitHalfOpen {
+				c.probes--
+			}
+		case c.state == CircuitClosed:
+			c.requests++
+
+			if failure {
+				c.failures++
+				c.consecutive++
+			} else {
+				c.consecutive = 0
+			}
+
+			if failure && b.trip(c) {
+				transition = b.change(key, c, CircuitOpen)
+			}
+		case c.state == CircuitHalfOpen:
+			if failure {
+				transition = b.change(key, c, CircuitOpen)
+				break
+			}
+
+			c.probeSuccess++
+
+			if c.probeSuccess >= b.options.HalfOpenProbes {
+				transition = b.change(key, c, CircuitClosed)
+			}
+		}
+	}
+
+	b.mu.Unlock()
+
+	b.notify(transition)
+
+}
+
+// trip checks whether closed circuit exceeded failure thresholds.
+func (b *circuitBreaker) trip(c *circuit) bool {
+	if c.consecutive >= b.options.ConsecutiveFailures {
+		return true
+	}
+
+	return b.options.FailureRatio > 0 &&
+		c.requests >= b.options.MinRequests &&
+		float64(c.failures)/float64(c.requests) >= b.options.FailureRatio
+
+}
+
+// idle reports closed circuit without requests in flight and failures
+// counted, so it is the same as new one. Must be called with lock held.
+func (b *circuitBreaker) idle(c *circuit, now time.Time) bool {
+	if c.state != CircuitClosed || c.inFlight > 0 {
+		return false
+	}
+
+	return c.failures == 0 ||
+		(b.options.Interval > 0 && now.Sub(c.changedAt) >= b.options.Interval)
+
+}
+
+// circuit returns circuit of given key, switching it to half-open once
+// cool-down is over and resetting closed circuit counts every interval.
+// Must be called with lock held.
+func (b *circuitBreaker) circuit(key string) (*circuit, *circuitTransition) {
+	now := b.options.now()
+
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{changedAt: now}
+		b.circuits[key] = c
+	}
+
+	switch c.state {
+	case CircuitOpen:
+		if now.Sub(c.changedAt) >= b.options.CoolDown {
+			return c, b.change(key, c, CircuitHalfOpen)
+		}
+	case CircuitClosed:
+		if b.options.Interval > 0 && now.Sub(c.changedAt) >= b.options.Interval {
+			c.reset(now)
+		}
+	}
+
+	return c, nil
+
+}
+
+// change switches circuit state. Must be called with lock held.
+func (b *circuitBreaker) change(key string, c *circuit, state CircuitState) *circuitTransition {
+	transition := &circuitTransition{
+		key:  key,
+		from: c.state,
+		to:   state,
+	}
+
+	c.state = state
+	c.reset(b.options.now())
+
+	return transition
+
+}
+
+// notify calls state change callback outside of lock.
+func (b *circuitBreaker) notify(transition *circuitTransition) {
+	if transition == nil || b.options.OnStateChange == nil {
+		return
+	}
+
+	b.options.OnStateChange(transition.key, transition.from, transition.to)
+
+}
+
+// reset starts new circuit generation.
+func (c *circuit) reset(now time.Time) {
+	c.generation++
+	c.changedAt = now
+	c.requests = 0
+	c.failures = 0
+	c.consecutive = 0
+	c.probes = 0
+	c.probeSuccess = 0
+
+}
+
+// isFailure reports errors, except context cancellation,
+// and 5xx status codes as failures.
+func isFailure(res *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+
+	return res != nil && res.StatusCode >= http.StatusInternalServerError
+
+}
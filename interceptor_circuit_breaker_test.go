@@ -0,0 +1,260 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	type transition struct {
+		key  string
+		from CircuitState
+		to   CircuitState
+	}
+
+	var (
+		transitions []transition
+		status      = map[string]int{}
+		sent        int
+	)
+
+	c := NewClient(
+		WithTransport(
+			RoundTripper(
+				func(req *http.Request) (*http.Response, error) {
+					sent++
+
+					return &http.Response{StatusCode: status[req.URL.Host], Body: http.NoBody}, nil
+				},
+			),
+		),
+		WithInterceptors(
+			CircuitBreaker(
+				CircuitBreakerOptions{
+					ConsecutiveFailures: 3,
+					CoolDown:            time.Minute,
+					HalfOpenProbes:      2,
+					OnStateChange: func(key string, from CircuitState, to CircuitState) {
+						transitions = append(transitions, transition{key: key, from: from, to: to})
+					},
+					now: func() time.Time { return now },
+				},
+			),
+		),
+	)
+
+	get := func(host string) error {
+		_, err := c.Request().Get(context.Background(), "http://"+host)
+
+		return err
+	}
+
+	status["a.example.com"] = http.StatusInternalServerError
+	status["b.example.com"] = http.StatusOK
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, get("a.example.com"))
+	}
+
+	assert.ErrorIs(t, get("a.example.com"), ErrCircuitOpen)
+	assert.NoError(t, get("b.example.com"))
+	assert.Equal(t, 4, sent)
+
+	now = now.Add(time.Minute)
+	status["a.example.com"] = http.StatusBadGateway
+
+	require.NoError(t, get("a.example.com"))
+	assert.ErrorIs(t, get("a.example.com"), ErrCircuitOpen)
+
+	now = now.Add(time.Minute)
+	status["a.example.com"] = http.StatusOK
+
+	require.NoError(t, get("a.example.com"))
+	require.NoError(t, get("a.example.com"))
+	require.NoError(t, get("a.example.com"))
+
+	assert.Equal(
+		t,
+		[]transition{
+			{key: "a.example.com", from: CircuitClosed, to: CircuitOpen},
+			{key: "a.example.com", from: CircuitOpen, to: CircuitHalfOpen},
+			{key: "a.example.com", from: CircuitHalfOpen, to: CircuitOpen},
+			{key: "a.example.com", from: CircuitOpen, to: CircuitHalfOpen},
+			{key: "a.example.com", from: CircuitHalfOpen, to: CircuitClosed},
+		},
+		transitions,
+	)
+
+}
+
+func TestCircuitBreaker_FailureRatio(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	breaker := &circuitBreaker{
+		options: CircuitBreakerOptions{
+			ConsecutiveFailures: 100,
+			FailureRatio:        0.5,
+			MinRequests:         4,
+			Interval:            time.Minute,
+			now:                 func() time.Time { return now },
+		}.withDefaults(),
+		circuits: make(map[string]*circuit),
+	}
+
+	request := func(outcome circuitOutcome) error {
+		generation, err := breaker.before("key")
+		if err != nil {
+			return err
+		}
+
+		breaker.after("key", generation, outcome)
+
+		return nil
+	}
+
+	for _, outcome := range []circuitOutcome{circuitFailure, circuitSuccess, circuitFailure} {
+		require.NoError(t, request(outcome))
+	}
+
+	now = now.Add(time.Minute)
+
+	for _, outcome := range []circuitOutcome{circuitSuccess, circuitFailure, circuitSuccess, circuitFailure} {
+		require.NoError(t, request(outcome))
+	}
+
+	assert.ErrorIs(t, request(circuitSuccess), ErrCircuitOpen)
+
+}
+
+func TestCircuitBreaker_HalfOpenProbes(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	breaker := &circuitBreaker{
+		options: CircuitBreakerOptions{
+			ConsecutiveFailures: 1,
+			CoolDown:            time.Second,
+			now:                 func() time.Time { return now },
+		}.withDefaults(),
+		circuits: make(map[string]*circuit),
+	}
+
+	generation, err := breaker.before("key")
+	require.NoError(t, err)
+	breaker.after("key", generation, circuitFailure)
+
+	now = now.Add(time.Second)
+
+	probe, err := breaker.before("key")
+	require.NoError(t, err)
+
+	_, err = breaker.before("key")
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+
+	breaker.after("key", generation, circuitFailure)
+	breaker.after("key", probe, circuitSuccess)
+
+	_, err = breaker.before("key")
+	assert.NoError(t, err)
+
+}
+
+func TestCircuitBreaker_Canceled(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	var states []CircuitState
+
+	c := NewClient(
+		WithTransport(
+			RoundTripper(
+				func(req *http.Request) (*http.Response, error) {
+					if err := req.Context().Err(); err != nil {
+						return nil, err
+					}
+
+					return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody}, nil
+				},
+			),
+		),
+		WithInterceptors(
+			CircuitBreaker(
+				CircuitBreakerOptions{
+					ConsecutiveFailures: 2,
+					CoolDown:            time.Minute,
+					OnStateChange: func(key string, from CircuitState, to CircuitState) {
+						states = append(states, to)
+					},
+					now: func() time.Time { return now },
+				},
+			),
+		),
+	)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	get := func(ctx context.Context) error {
+		_, err := c.Request().Get(ctx, "http://localhost:8080")
+
+		return err
+	}
+
+	// Canceled request does not reset consecutive failures.
+	assert.NoError(t, get(context.Background()))
+	assert.ErrorIs(t, get(canceled), context.Canceled)
+	assert.NoError(t, get(context.Background()))
+	assert.Equal(t, []CircuitState{CircuitOpen}, states)
+
+	now = now.Add(time.Minute)
+
+	// Canceled probe neither closes circuit nor keeps probe slot.
+	assert.ErrorIs(t, get(canceled), context.Canceled)
+	assert.Equal(t, []CircuitState{CircuitOpen, CircuitHalfOpen}, states)
+
+	assert.NoError(t, get(context.Background()))
+	assert.Equal(t, []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitOpen}, states)
+
+}
+
+func TestCircuitBreaker_Evict(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	breaker := &circuitBreaker{
+		options: CircuitBreakerOptions{
+			ConsecutiveFailures: 1,
+			now:                 func() time.Time { return now },
+		}.withDefaults(),
+		circuits: make(map[string]*circuit),
+	}
+
+	for _, key := range []string{"success", "in-flight", "open"} {
+		_, err := breaker.before(key)
+		require.NoError(t, err)
+	}
+
+	breaker.after("success", 0, circuitSuccess)
+	breaker.after("open", 0, circuitFailure)
+
+	now = now.Add(keySweepInterval)
+
+	_, err := breaker.before("other")
+	require.NoError(t, err)
+
+	assert.Len(t, breaker.circuits, 3)
+	assert.NotContains(t, breaker.circuits, "success")
+
+}
+
+func TestIsFailure(t *testing.T) {
+	assert.True(t, isFailure(nil, errors.New("connection refused")))
+	assert.False(t, isFailure(nil, context.Canceled))
+	assert.True(t, isFailure(&http.Response{StatusCode: http.StatusServiceUnavailable}, nil))
+	assert.False(t, isFailure(&http.Response{StatusCode: http.StatusNotFound}, nil))
+
+}
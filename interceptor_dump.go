@@ -0,0 +1,270 @@
+package request
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+)
+
+const DefaultDumpMaxBodySize = 64 << 10
+
+type dumpContextKey struct{}
+
+// DumpOptions configures Dump interceptor. Zero values are replaced by defaults.
+type DumpOptions struct {
+	// All dumps every request unless it is disabled by WithDump,
+	// otherwise only requests enabled by WithDump are dumped.
+	All bool
+
+	// MaxBodySize is number of dumped body bytes, the rest is truncated.
+	// Negative value disables dumping of bodies.
+	MaxBodySize int
+
+	// Redaction configures redacted values in addition to always redacted ones.
+	Redaction Redaction
+}
+
+// withDefaults returns options with zero values replaced by defaults.
+func (o DumpOptions) withDefaults() DumpOptions {
+	if o.MaxBodySize == 0 {
+		o.MaxBodySize = DefaultDumpMaxBodySize
+	}
+
+	return o
+
+}
+
+// WithDump enables or disables dumping of requests made with context by Dump interceptor.
+func WithDump(ctx context.Context, enabled bool) context.Context {
+	return context.WithValue(ctx, dumpContextKey{}, enabled)
+}
+
+// Dump interceptor writes request and Response as they are sent over
+// the wire, see httputil.DumpRequestOut and httputil.DumpResponse, with
+// sensitive values redacted as by Logging interceptor. Bodies are copied
+// to dump while they are sent and read by caller, so streamed Response
+// is not held back, and dump is written once Response body is read to
+// the end or closed.
+func Dump(w io.Writer, options DumpOptions) Interceptor {
+	options = options.withDefaults()
+	redactor := newRedactor(options.Redaction)
+
+	var mu sync.Mutex
+
+	write := func(dump *bytes.Buffer) {
+		dump.WriteString("\n\n")
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		// Failed dump does not fail request.
+		_, _ = w.Write(dump.Bytes())
+	}
+
+	return func(tripper http.RoundTripper) http.RoundTripper {
+		return RoundTripper(
+			func(req *http.Request) (*http.Response, error) {
+				enabled, ok := req.Context().Value(dumpContextKey{}).(bool)
+				if !ok {
+					enabled = options.All
+				}
+
+				if !enabled {
+					return tripper.RoundTrip(req)
+				}
+
+				dump := &bytes.Buffer{}
+
+				req, requestBody := dumpRequest(dump, req, options.MaxBodySize, redactor)
+
+				res, err := tripper.RoundTrip(req)
+
+				dump.WriteString(requestBody())
+
+				if err != nil {
+					fmt.Fprintf(dump, "\n\n%s", err)
+					write(dump)
+
+					return nil, err
+				}
+
+				dump.WriteString("\n\n")
+				dumpResponse(dump, res, options.MaxBodySize, redactor, write)
+
+				return res, nil
+
+			},
+		)
+	}
+
+}
+
+// dumpCapture keeps up to limit bytes written to it.
+type dumpCapture struct {
+	mu        sync.Mutex
+	limit     int
+	data      []byte
+	truncated bool
+}
+
+func (c *dumpCapture) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := min(len(p), c.limit-len(c.data))
+	c.data = append(c.data, p[:n]...)
+
+	if n < len(p) {
+		c.truncated = true
+	}
+
+	return len(p), nil
+
+}
+
+// body returns redacted and truncated captured body.
+func (c *dumpCapture) body(contentType string, redactor *redactor) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return formatBody(redactor.body(contentType, c.data), c.truncated)
+
+}
+
+// dumpBody copies Response body to capture while it is read by caller
+// and calls done once it is read to the end or closed.
+type dumpBody struct {
+	io.ReadCloser
+	capture *dumpCapture
+	once    sync.Once
+	done    func()
+}
+
+func (b *dumpBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	_, _ = b.capture.Write(p[:n])
+
+	if err == io.EOF {
+		b.once.Do(b.done)
+	}
+
+	return n, err
+
+}
+
+func (b *dumpBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+
+	return err
+
+}
+
+// dumpRequest writes redacted request to dump and returns request to be
+// sent and function returning its body dump. Replayable body is read from
+// its copy, otherwise it is copied while it is sent.
+func dumpRequest(
+	dump *bytes.Buffer,
+	req *http.Request,
+	limit int,
+	redactor *redactor,
+) (*http.Request, func() string) {
+	noBody := func() string { return "" }
+
+	copied := req.Clone(req.Context())
+	copied.Header = redactor.header(req.Header)
+	copied.URL.User = nil
+	copied.URL.RawQuery = redactor.rawQuery(req.URL.RawQuery)
+
+	b, err := httputil.DumpRequestOut(copied, false)
+	if err != nil {
+		fmt.Fprintf(dump, "dump request: %s", err)
+
+		return req, noBody
+	}
+
+	dump.Write(b)
+
+	if limit < 0 || req.Body == nil || req.Body == http.NoBody {
+		return req, noBody
+	}
+
+	contentType := req.Header.Get(ContentType)
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return req, noBody
+		}
+		defer body.Close()
+
+		peeked, truncated, _, err := peekBody(body, limit)
+		if err != nil {
+			return req, noBody
+		}
+
+		formatted := formatBody(redactor.body(contentType, peeked), truncated)
+
+		return req, func() string { return formatted }
+	}
+
+	capture := &dumpCapture{limit: limit}
+
+	body := req.Body
+
+	req = req.Clone(req.Context())
+	req.Body = readCloser(io.TeeReader(body, capture), body)
+
+	return req, func() string { return capture.body(contentType, redactor) }
+
+}
+
+// dumpResponse writes redacted Response HEADER to dump, body is copied
+// while it is read by caller and dump is written by write once it is
+// read to the end or closed.
+func dumpResponse(
+	dump *bytes.Buffer,
+	res *http.Response,
+	limit int,
+	redactor *redactor,
+	write func(dump *bytes.Buffer),
+) {
+	copied := *res
+	copied.Header = redactor.header(res.Header)
+
+	b, err := httputil.DumpResponse(&copied, false)
+	if err != nil {
+		fmt.Fprintf(dump, "dump response: %s", err)
+		write(dump)
+
+		return
+	}
+
+	dump.Write(b)
+
+	if limit < 0 || res.Body == nil || res.Body == http.NoBody ||
+		res.StatusCode == http.StatusSwitchingProtocols ||
+		(res.Request != nil && res.Request.Method == http.MethodHead) {
+		write(dump)
+
+		return
+	}
+
+	capture := &dumpCapture{limit: limit}
+	contentType := res.Header.Get(ContentType)
+
+	res.Body = &dumpBody{
+		ReadCloser: res.Body,
+		capture:    capture,
+		// Body closed before the end is dumped as far as it is read.
+		done: func() {
+			dump.WriteString(capture.body(contentType, redactor))
+			write(dump)
+		},
+	}
+
+}
@@ -0,0 +1,287 @@
+package request
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	type args struct {
+		options DumpOptions
+		ctx     func(context.Context) context.Context
+		body    io.Reader
+	}
+
+	type want struct {
+		dump     []string
+		excluded []string
+	}
+
+	type test struct {
+		name string
+		args args
+		want want
+	}
+
+	tests := []test{
+		{
+			name: "Request is dumped with secrets redacted",
+			args: args{
+				options: DumpOptions{All: true, Redaction: Redaction{Fields: []string{"pin"}}},
+				body:    strings.NewReader(`{"user":"john","password":"secret","pin":1234}`),
+			},
+			want: want{
+				dump: []string{
+					"POST /todos?page=1&access_token=%5BREDACTED%5D HTTP/1.1\r\n",
+					"Host: localhost:8080\r\n",
+					"Authorization: [REDACTED]\r\n",
+					"Content-Length: 46\r\n",
+					`{"user":"john","password":"[REDACTED]","pin":"[REDACTED]"}`,
+					"HTTP/1.1 200 OK\r\n",
+					"Set-Cookie: [REDACTED]\r\n",
+					`{"id":1,"token":"[REDACTED]"}`,
+				},
+				excluded: []string{"secret", "abc", "def", "ghi", "1234"},
+			},
+		},
+		{
+			name: "Not replayable request body is copied while sent",
+			args: args{
+				options: DumpOptions{All: true},
+				body:    io.MultiReader(strings.NewReader(`{"user":"john"}`)),
+			},
+			want: want{
+				dump: []string{
+					"Transfer-Encoding: chunked\r\n",
+					`{"user":"john"}`,
+				},
+			},
+		},
+		{
+			name: "Bodies are truncated",
+			args: args{
+				options: DumpOptions{All: true, MaxBodySize: 8},
+				body:    strings.NewReader(`{"user":"john"}`),
+			},
+			want: want{
+				dump: []string{
+					`{"user":...(truncated)`,
+					`{"id":1,...(truncated)`,
+				},
+			},
+		},
+		{
+			name: "Bodies are not dumped",
+			args: args{
+				options: DumpOptions{All: true, MaxBodySize: -1},
+				body:    strings.NewReader(`{"user":"john"}`),
+			},
+			want: want{
+				dump: []string{
+					"POST /todos",
+					"HTTP/1.1 200 OK\r\n",
+				},
+				excluded: []string{"john", `"id"`},
+			},
+		},
+		{
+			name: "Request is enabled by context",
+			args: args{
+				ctx: func(ctx context.Context) context.Context {
+					return WithDump(ctx, true)
+				},
+			},
+			want: want{
+				dump: []string{
+					"POST /todos",
+				},
+			},
+		},
+		{
+			name: "Request is disabled by context",
+			args: args{
+				options: DumpOptions{All: true},
+				ctx: func(ctx context.Context) context.Context {
+					return WithDump(ctx, false)
+				},
+			},
+		},
+		{
+			name: "Request is not dumped by default",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				dump     bytes.Buffer
+				received string
+			)
+
+			c := NewClient(
+				WithTransport(
+					RoundTripper(
+						func(req *http.Request) (*http.Response, error) {
+							if req.Body != nil {
+								body, err := io.ReadAll(req.Body)
+								require.NoError(t, err)
+
+								received = string(body)
+							}
+
+							return &http.Response{
+								Status:     "200 OK",
+								StatusCode: http.StatusOK,
+								Proto:      "HTTP/1.1",
+								ProtoMajor: 1,
+								ProtoMinor: 1,
+								Header: http.Header{
+									ContentType:  {ApplicationJSON},
+									"Set-Cookie": {"session=def"},
+								},
+								ContentLength: -1,
+								Body:          io.NopCloser(strings.NewReader(`{"id":1,"token":"ghi"}`)),
+								Request:       req,
+							}, nil
+						},
+					),
+				),
+				WithInterceptors(Dump(&dump, tc.args.options)),
+			)
+
+			ctx := context.Background()
+			if tc.args.ctx != nil {
+				ctx = tc.args.ctx(ctx)
+			}
+
+			var body string
+			if tc.args.body != nil {
+				b, err := io.ReadAll(tc.args.body)
+				require.NoError(t, err)
+
+				body = string(b)
+
+				// Reader type decides if request body is replayable.
+				if _, ok := tc.args.body.(*strings.Reader); ok {
+					tc.args.body = strings.NewReader(body)
+				} else {
+					tc.args.body = io.MultiReader(strings.NewReader(body))
+				}
+			}
+
+			res, err := c.Request().
+				WithBearerAuth("abc").
+				WithHeader(ContentType, ApplicationJSON).
+				Post(ctx, "http://localhost:8080/todos?page=1&access_token=secret", tc.args.body)
+			require.NoError(t, err)
+
+			assert.Equal(t, body, received)
+
+			var todo struct {
+				ID    int    `json:"id"`
+				Token string `json:"token"`
+			}
+
+			require.NoError(t, res.Decoder().Decode(&todo))
+			assert.Equal(t, 1, todo.ID)
+			assert.Equal(t, "ghi", todo.Token)
+
+			// Response is dumped once its body is closed.
+			require.NoError(t, res.Body.Close())
+
+			if len(tc.want.dump) == 0 {
+				assert.Empty(t, dump.String())
+			}
+
+			for _, s := range tc.want.dump {
+				assert.Contains(t, dump.String(), s)
+			}
+
+			for _, s := range tc.want.excluded {
+				assert.NotContains(t, dump.String(), s)
+			}
+
+		})
+	}
+
+}
+
+func TestDump_Streaming(t *testing.T) {
+	unblock := make(chan struct{})
+
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(ContentType, "text/event-stream")
+				_, _ = w.Write([]byte("data: first\n\n"))
+				w.(http.Flusher).Flush()
+
+				<-unblock
+
+				_, _ = w.Write([]byte("data: second\n\n"))
+			},
+		),
+	)
+	defer server.Close()
+	defer close(unblock)
+
+	var (
+		mu   sync.Mutex
+		dump bytes.Buffer
+	)
+
+	c := NewClient(
+		WithInterceptors(
+			Dump(
+				writerFunc(
+					func(p []byte) (int, error) {
+						mu.Lock()
+						defer mu.Unlock()
+
+						return dump.Write(p)
+					},
+				),
+				DumpOptions{All: true},
+			),
+		),
+	)
+
+	// Response is returned before the stream ends.
+	res, err := c.Request().Get(context.Background(), server.URL)
+	require.NoError(t, err)
+
+	line, err := bufio.NewReader(res.Body).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "data: first\n", line)
+
+	mu.Lock()
+	assert.Empty(t, dump.String())
+	mu.Unlock()
+
+	unblock <- struct{}{}
+
+	_, err = io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	mu.Lock()
+	assert.Contains(t, dump.String(), "GET / HTTP/1.1\r\n")
+	assert.Contains(t, dump.String(), "Content-Type: text/event-stream\r\n")
+	assert.Contains(t, dump.String(), "data: first\n\ndata: second\n\n")
+	mu.Unlock()
+
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
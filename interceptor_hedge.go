@@ -0,0 +1,258 @@
+package request
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultHedgeDelay         = 100 * time.Millisecond
+	DefaultHedgeBudgetPercent = 10
+	DefaultHedgeWindow        = 100
+
+	// hedgeMinSamples is number of latency samples
+	// percentile delay is calculated from.
+	hedgeMinSamples = 10
+
+	// hedgeMaxTokens limits hedges made at once after idle period.
+	hedgeMaxTokens = 10
+)
+
+// safeMethods are hedged by default, see RFC 9110 section 9.2.1.
+var safeMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodOptions,
+	http.MethodTrace,
+}
+
+// HedgeOptions configures Hedge interceptor. Zero values are replaced by defaults.
+type HedgeOptions struct {
+	// Delay is time to wait for response before sending hedged request.
+	Delay time.Duration
+
+	// Percentile, e.g. 0.95, sets delay to the percentile of observed
+	// latencies once enough of them is observed, Delay is used before.
+	// Zero disables it.
+	Percentile float64
+
+	// Window is number of latest latencies percentile is calculated from.
+	Window int
+
+	// BudgetPercent is maximal percent of hedged requests to all requests.
+	BudgetPercent float64
+
+	// Methods are request methods allowed to be hedged, safe methods by default.
+	Methods []string
+}
+
+// withDefaults returns options with zero values replaced by defaults.
+func (o HedgeOptions) withDefaults() HedgeOptions {
+	if o.Delay <= 0 {
+		o.Delay = DefaultHedgeDelay
+	}
+
+	if o.Window <= 0 {
+		o.Window = DefaultHedgeWindow
+	}
+
+	if o.BudgetPercent <= 0 {
+		o.BudgetPercent = DefaultHedgeBudgetPercent
+	}
+
+	if len(o.Methods) == 0 {
+		o.Methods = safeMethods
+	}
+
+	return o
+
+}
+
+type hedge struct {
+	options HedgeOptions
+
+	mu        sync.Mutex
+	tokens    float64
+	latencies []time.Duration
+	next      int
+}
+
+type hedgeResult struct {
+	res     *http.Response
+	err     error
+	cancel  context.CancelFunc
+	latency time.Duration
+}
+
+// Hedge interceptor sends copy of request if response is not received
+// within delay, fixed or latency percentile, and returns whichever
+// response comes first. The other request is canceled and its response
+// drained. Only safe methods with replayable body are hedged, and hedged
+// requests are limited by budget percent of all requests.
+func Hedge(options HedgeOptions) Interceptor {
+	h := &hedge{
+		options: options.withDefaults(),
+	}
+
+	return func(tripper http.RoundTripper) http.RoundTripper {
+		return RoundTripper(
+			func(req *http.Request) (*http.Response, error) {
+				if !slices.Contains(h.options.Methods, req.Method) ||
+					(req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+					return tripper.RoundTrip(req)
+				}
+
+				return h.roundTrip(tripper, req)
+
+			},
+		)
+	}
+
+}
+
+func (h *hedge) roundTrip(tripper http.RoundTripper, req *http.Request) (*http.Response, error) {
+	h.earn()
+
+	results := make(chan hedgeResult, 2)
+
+	send := func(first bool) {
+		ctx, cancel := context.WithCancel(req.Context())
+		copyReq := req.Clone(ctx)
+
+		if !first && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				results <- hedgeResult{err: err, cancel: cancel}
+				return
+			}
+
+			copyReq.Body = body
+		}
+
+		started := time.Now()
+		res, err := tripper.RoundTrip(copyReq)
+
+		results <- hedgeResult{res: res, err: err, cancel: cancel, latency: time.Since(started)}
+	}
+
+	go send(true)
+
+	timer := time.NewTimer(h.delay())
+	defer timer.Stop()
+
+	pending := 1
+
+	var result hedgeResult
+
+	for {
+		select {
+		case result = <-results:
+			pending--
+		case <-timer.C:
+			if h.spend() {
+				pending++
+
+				go send(false)
+			}
+
+			continue
+		}
+
+		if result.err == nil || pending == 0 {
+			break
+		}
+
+		result.cancel()
+	}
+
+	// Loser is canceled and drained in background.
+	go func() {
+		for ; pending > 0; pending-- {
+			loser := <-results
+			loser.cancel()
+			drainBody(loser.res)
+		}
+	}()
+
+	if result.err != nil {
+		result.cancel()
+
+		return nil, result.err
+	}
+
+	h.observe(result.latency)
+
+	result.res.Body = cancelOnClose(result.res.Body, result.cancel)
+
+	return result.res, nil
+
+}
+
+// earn adds hedge budget of single request.
+func (h *hedge) earn() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.tokens = min(h.tokens+h.options.BudgetPercent/100, hedgeMaxTokens)
+
+}
+
+// spend takes budget of single hedged request if available.
+func (h *hedge) spend() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.tokens < 1 {
+		return false
+	}
+
+	h.tokens--
+
+	return true
+
+}
+
+// observe records response latency.
+func (h *hedge) observe(latency time.Duration) {
+	if h.options.Percentile <= 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.latencies) < h.options.Window {
+		h.latencies = append(h.latencies, latency)
+		return
+	}
+
+	h.latencies[h.next] = latency
+	h.next = (h.next + 1) % h.options.Window
+
+}
+
+// delay returns delay before hedged request.
+func (h *hedge) delay() time.Duration {
+	if h.options.Percentile <= 0 {
+		return h.options.Delay
+	}
+
+	h.mu.Lock()
+	latencies := slices.Clone(h.latencies)
+	h.mu.Unlock()
+
+	if len(latencies) < hedgeMinSamples {
+		return h.options.Delay
+	}
+
+	slices.Sort(latencies)
+
+	i := int(math.Ceil(h.options.Percentile*float64(len(latencies)))) - 1
+
+	return latencies[min(max(i, 0), len(latencies)-1)]
+
+}
@@ -0,0 +1,180 @@
+package request
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testClosedBody struct {
+	io.Reader
+	closed *atomic.Bool
+}
+
+func (b testClosedBody) Close() error {
+	b.closed.Store(true)
+
+	return nil
+
+}
+
+func TestHedge(t *testing.T) {
+	type args struct {
+		options  HedgeOptions
+		method   string
+		requests int
+		slow     bool
+	}
+
+	type want struct {
+		body  string
+		calls int
+	}
+
+	type test struct {
+		name string
+		args args
+		want want
+	}
+
+	tests := []test{
+		{
+			name: "Hedged request wins",
+			args: args{
+				options:  HedgeOptions{Delay: 10 * time.Millisecond, BudgetPercent: 100},
+				method:   http.MethodGet,
+				requests: 1,
+				slow:     true,
+			},
+			want: want{
+				body:  "2",
+				calls: 2,
+			},
+		},
+		{
+			name: "Fast response is not hedged",
+			args: args{
+				options:  HedgeOptions{Delay: time.Minute, BudgetPercent: 100},
+				method:   http.MethodGet,
+				requests: 1,
+			},
+			want: want{
+				body:  "1",
+				calls: 1,
+			},
+		},
+		{
+			name: "Unsafe method is not hedged",
+			args: args{
+				options:  HedgeOptions{Delay: 10 * time.Millisecond, BudgetPercent: 100},
+				method:   http.MethodPost,
+				requests: 1,
+				slow:     true,
+			},
+			want: want{
+				body:  "1",
+				calls: 1,
+			},
+		},
+		{
+			name: "Hedges are limited by budget",
+			args: args{
+				options:  HedgeOptions{Delay: 10 * time.Millisecond, BudgetPercent: 50},
+				method:   http.MethodGet,
+				requests: 2,
+				slow:     true,
+			},
+			want: want{
+				body:  "3",
+				calls: 3,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				mu     sync.Mutex
+				calls  int
+				closed atomic.Bool
+			)
+
+			c := NewClient(
+				WithTransport(
+					RoundTripper(
+						func(req *http.Request) (*http.Response, error) {
+							mu.Lock()
+							calls++
+							call := calls
+							mu.Unlock()
+
+							// The first call of every request is slow, hedges are fast.
+							if tc.args.slow && (call == 1 || (call == 2 && tc.args.requests == 2)) {
+								select {
+								case <-req.Context().Done():
+									return &http.Response{
+										StatusCode: http.StatusOK,
+										Body:       testClosedBody{Reader: strings.NewReader("slow"), closed: &closed},
+									}, nil
+								case <-time.After(50 * time.Millisecond):
+								}
+							}
+
+							return &http.Response{
+								StatusCode: http.StatusOK,
+								Body:       io.NopCloser(strings.NewReader(strconv.Itoa(call))),
+							}, nil
+						},
+					),
+				),
+				WithInterceptors(Hedge(tc.args.options)),
+			)
+
+			var body []byte
+			for i := 0; i < tc.args.requests; i++ {
+				res, err := c.Request().Do(context.Background(), tc.args.method, "http://localhost:8080", nil)
+				require.NoError(t, err)
+
+				body, err = io.ReadAll(res.Body)
+				require.NoError(t, err)
+				require.NoError(t, res.Body.Close())
+			}
+
+			assert.Equal(t, tc.want.body, string(body))
+
+			mu.Lock()
+			assert.Equal(t, tc.want.calls, calls)
+			mu.Unlock()
+
+			if tc.want.calls > tc.args.requests {
+				assert.Eventually(t, closed.Load, time.Second, time.Millisecond)
+			}
+
+		})
+	}
+
+}
+
+func TestHedge_PercentileDelay(t *testing.T) {
+	h := &hedge{
+		options: HedgeOptions{Percentile: 0.9, Window: 20}.withDefaults(),
+	}
+
+	assert.Equal(t, DefaultHedgeDelay, h.delay())
+
+	for i := 1; i <= 30; i++ {
+		h.observe(time.Duration(i) * time.Millisecond)
+	}
+
+	assert.Len(t, h.latencies, 20)
+	assert.Equal(t, 28*time.Millisecond, h.delay())
+
+}
@@ -0,0 +1,50 @@
+package request
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// keySweepInterval is period keyed interceptors evict state of idle keys
+// with, so it does not grow unbounded with number of hosts or tenants.
+const keySweepInterval = time.Minute
+
+type requestKeyContextKey struct{}
+
+// WithRequestKey sets key to context, it is used by interceptors keyed
+// with KeyByContext, e.g. to rate limit requests per tenant.
+func WithRequestKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, requestKeyContextKey{}, key)
+}
+
+// KeyByHost keys request by its host.
+func KeyByHost(req *http.Request) string {
+	return req.URL.Host
+}
+
+// KeyByContext keys request by key set to its context by WithRequestKey.
+func KeyByContext(req *http.Request) string {
+	key, _ := req.Context().Value(requestKeyContextKey{}).(string)
+
+	return key
+
+}
+
+// keySweeper tells when state of idle keys is due to be evicted.
+type keySweeper struct {
+	swept time.Time
+}
+
+// due reports whether sweep interval passed since last sweep.
+// Must be called with lock of keyed state held.
+func (s *keySweeper) due(now time.Time) bool {
+	if now.Sub(s.swept) < keySweepInterval {
+		return false
+	}
+
+	s.swept = now
+
+	return true
+
+}
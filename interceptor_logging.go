@@ -0,0 +1,200 @@
+package request
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+)
+
+const DefaultLoggingMaxBodySize = 1 << 10
+
+// LoggingOptions configures Logging interceptor. Zero values are replaced by defaults.
+type LoggingOptions struct {
+	// Headers logs request and Response HEADERS.
+	Headers bool
+
+	// Bodies logs request and Response bodies up to MaxBodySize bytes.
+	// Request body is logged only if it is replayable, Response body is
+	// peeked, so it is still read by caller, which holds Response until
+	// MaxBodySize bytes are received.
+	Bodies bool
+
+	// MaxBodySize is number of logged body bytes, the rest is truncated.
+	MaxBodySize int
+
+	// Redaction configures redacted values in addition to always redacted ones.
+	Redaction Redaction
+
+	// Level returns log level of request result, by default errors and 5xx
+	// status codes are logged as errors, 4xx ones as warnings, others as info.
+	Level func(res *http.Response, err error) slog.Level
+}
+
+// withDefaults returns options with zero values replaced by defaults.
+func (o LoggingOptions) withDefaults() LoggingOptions {
+	if o.MaxBodySize <= 0 {
+		o.MaxBodySize = DefaultLoggingMaxBodySize
+	}
+
+	if o.Level == nil {
+		o.Level = logLevel
+	}
+
+	return o
+
+}
+
+// Logging interceptor logs method, redacted URL, status code, duration,
+// body sizes and attempt number of every request with logger. Placed after
+// Retry interceptor it logs every attempt, before it logs final result
+// with number of made attempts.
+func Logging(logger *slog.Logger, options LoggingOptions) Interceptor {
+	options = options.withDefaults()
+	redactor := newRedactor(options.Redaction)
+
+	return func(tripper http.RoundTripper) http.RoundTripper {
+		return RoundTripper(
+			func(req *http.Request) (*http.Response, error) {
+				ctx := req.Context()
+				recorder := attemptsFromContext(ctx)
+				attempt := recorder.get().Count + 1
+
+				attrs := []slog.Attr{
+					slog.String("method", req.Method),
+					slog.String("url", redactor.url(req.URL)),
+				}
+
+				if req.ContentLength > 0 {
+					attrs = append(attrs, slog.Int64("request_size", req.ContentLength))
+				}
+
+				if options.Headers {
+					attrs = append(attrs, headerAttr("request_headers", redactor.header(req.Header)))
+				}
+
+				if options.Bodies {
+					if body, ok := requestBody(req, options.MaxBodySize, redactor); ok {
+						attrs = append(attrs, slog.String("request_body", body))
+					}
+				}
+
+				started := time.Now()
+
+				res, err := tripper.RoundTrip(req)
+
+				attrs = append(
+					attrs,
+					slog.Duration("duration", time.Since(started)),
+					slog.Int("attempt", max(attempt, recorder.get().Count)),
+				)
+
+				if err != nil {
+					attrs = append(attrs, slog.String("error", err.Error()))
+				}
+
+				if res != nil {
+					attrs = append(attrs, slog.Int("status", res.StatusCode))
+
+					if res.ContentLength >= 0 {
+						attrs = append(attrs, slog.Int64("response_size", res.ContentLength))
+					}
+
+					if options.Headers {
+						attrs = append(attrs, headerAttr("response_headers", redactor.header(res.Header)))
+					}
+
+					if options.Bodies && res.StatusCode != http.StatusSwitchingProtocols {
+						var body string
+						body, res.Body = responseBody(res, options.MaxBodySize, redactor)
+
+						attrs = append(attrs, slog.String("response_body", body))
+					}
+				}
+
+				logger.LogAttrs(ctx, options.Level(res, err), "http request", attrs...)
+
+				return res, err
+
+			},
+		)
+	}
+
+}
+
+// requestBody returns redacted and truncated request body if it is replayable.
+func requestBody(req *http.Request, limit int, redactor *redactor) (string, bool) {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody == nil {
+		return "", false
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return "", false
+	}
+	defer body.Close()
+
+	peeked, truncated, _, err := peekBody(body, limit)
+	if err != nil {
+		return "", false
+	}
+
+	return formatBody(redactor.body(req.Header.Get(ContentType), peeked), truncated), true
+
+}
+
+// responseBody returns redacted and truncated Response body
+// and body to be read by caller.
+func responseBody(res *http.Response, limit int, redactor *redactor) (string, io.ReadCloser) {
+	peeked, truncated, body, _ := peekBody(res.Body, limit)
+
+	return formatBody(redactor.body(res.Header.Get(ContentType), peeked), truncated), body
+
+}
+
+func formatBody(body []byte, truncated bool) string {
+	if truncated {
+		return string(body) + "...(truncated)"
+	}
+
+	return string(body)
+
+}
+
+// headerAttr returns group of HEADER values sorted by name.
+func headerAttr(key string, header http.Header) slog.Attr {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	attrs := make([]any, 0, len(header))
+	for _, name := range names {
+		values := header[name]
+
+		if len(values) == 1 {
+			attrs = append(attrs, slog.String(name, values[0]))
+		} else {
+			attrs = append(attrs, slog.Any(name, values))
+		}
+	}
+
+	return slog.Group(key, attrs...)
+
+}
+
+// logLevel chooses log level by status code class.
+func logLevel(res *http.Response, err error) slog.Level {
+	switch {
+	case err != nil || res == nil || res.StatusCode >= http.StatusInternalServerError:
+		return slog.LevelError
+	case res.StatusCode >= http.StatusBadRequest:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+
+}
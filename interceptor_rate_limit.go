@@ -0,0 +1,200 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	ErrRateLimited = errors.New("rate limit exceeded")
+)
+
+// RateLimitOptions configures RateLimit interceptor.
+type RateLimitOptions struct {
+	// Rate is number of requests per second, zero means no limit.
+	Rate float64
+
+	// Burst is number of requests sent at once, 1 by default.
+	Burst int
+
+	// Key returns bucket key of request, nil means single global bucket.
+	// See KeyByHost and KeyByContext.
+	Key func(req *http.Request) string
+
+	// Wait waits for request turn under request context instead
+	// of failing fast with ErrRateLimited. Request which would not
+	// get its turn before context deadline fails fast anyway.
+	Wait bool
+
+	now func() time.Time
+}
+
+// withDefaults returns options with zero values replaced by defaults.
+func (o RateLimitOptions) withDefaults() RateLimitOptions {
+	if o.Burst <= 0 {
+		o.Burst = 1
+	}
+
+	if o.Key == nil {
+		o.Key = func(*http.Request) string { return "" }
+	}
+
+	if o.now == nil {
+		o.now = time.Now
+	}
+
+	return o
+
+}
+
+// tokenBucket is filled with rate tokens per second up to burst,
+// every request takes single token. Tokens go negative for requests
+// waiting for their turn.
+type tokenBucket struct {
+	rate    float64
+	burst   float64
+	tokens  float64
+	updated time.Time
+}
+
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		rate:    rate,
+		burst:   float64(burst),
+		tokens:  float64(burst),
+		updated: now,
+	}
+
+}
+
+// take takes token and returns delay until it is available, token
+// is not taken if delay exceeds maxWait.
+func (b *tokenBucket) take(now time.Time, maxWait time.Duration) (time.Duration, bool) {
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.updated = now
+	}
+
+	var wait time.Duration
+	if b.tokens < 1 {
+		wait = time.Duration(math.Ceil((1 - b.tokens) / b.rate * float64(time.Second)))
+	}
+
+	if wait > maxWait {
+		return wait, false
+	}
+
+	b.tokens--
+
+	return wait, true
+
+}
+
+// full reports bucket is refilled up to burst, so it is the same as new one.
+func (b *tokenBucket) full(now time.Time) bool {
+	return b.tokens+now.Sub(b.updated).Seconds()*b.rate >= b.burst
+}
+
+// put returns token taken by canceled request.
+func (b *tokenBucket) put() {
+	b.tokens = min(b.burst, b.tokens+1)
+}
+
+type rateLimit struct {
+	options RateLimitOptions
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	sweeper keySweeper
+}
+
+// RateLimit interceptor limits request rate with token bucket, global,
+// per host or per context key. Request exceeding the rate either waits
+// for its turn or fails fast with ErrRateLimited. Full buckets are
+// evicted every minute.
+func RateLimit(options RateLimitOptions) Interceptor {
+	limit := &rateLimit{
+		options: options.withDefaults(),
+		buckets: make(map[string]*tokenBucket),
+	}
+
+	return func(tripper http.RoundTripper) http.RoundTripper {
+		if limit.options.Rate <= 0 {
+			return tripper
+		}
+
+		return RoundTripper(
+			func(req *http.Request) (*http.Response, error) {
+				if err := limit.wait(req); err != nil {
+					closeRequestBody(req)
+
+					return nil, err
+				}
+
+				return tripper.RoundTrip(req)
+
+			},
+		)
+	}
+
+}
+
+// wait takes token of request bucket, waiting for it if allowed.
+func (l *rateLimit) wait(req *http.Request) error {
+	ctx := req.Context()
+	key := l.options.Key(req)
+
+	var maxWait time.Duration
+	if l.options.Wait {
+		maxWait = time.Duration(math.MaxInt64)
+
+		if deadline, ok := ctx.Deadline(); ok {
+			maxWait = deadline.Sub(l.options.now())
+		}
+	}
+
+	l.mu.Lock()
+
+	if now := l.options.now(); l.sweeper.due(now) {
+		maps.DeleteFunc(
+			l.buckets,
+			func(_ string, bucket *tokenBucket) bool {
+				return bucket.full(now)
+			},
+		)
+	}
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = newTokenBucket(l.options.Rate, l.options.Burst, l.options.now())
+		l.buckets[key] = bucket
+	}
+
+	wait, ok := bucket.take(l.options.now(), maxWait)
+
+	l.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("%w: %s: retry in %s", ErrRateLimited, req.URL.Redacted(), wait)
+	}
+
+	if wait == 0 {
+		return nil
+	}
+
+	if err := sleepWithContext(ctx, wait); err != nil {
+		l.mu.Lock()
+		bucket.put()
+		l.mu.Unlock()
+
+		return err
+	}
+
+	return nil
+
+}
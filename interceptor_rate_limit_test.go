@@ -0,0 +1,230 @@
+package request
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	bucket := newTokenBucket(10, 2, now)
+
+	for i := 0; i < 2; i++ {
+		wait, ok := bucket.take(now, 0)
+		assert.True(t, ok)
+		assert.Zero(t, wait)
+	}
+
+	wait, ok := bucket.take(now, 0)
+	assert.False(t, ok)
+	assert.Equal(t, 100*time.Millisecond, wait)
+
+	wait, ok = bucket.take(now, time.Second)
+	assert.True(t, ok)
+	assert.Equal(t, 100*time.Millisecond, wait)
+
+	wait, ok = bucket.take(now, time.Second)
+	assert.True(t, ok)
+	assert.Equal(t, 200*time.Millisecond, wait)
+
+	bucket.put()
+
+	wait, ok = bucket.take(now.Add(50*time.Millisecond), time.Second)
+	assert.True(t, ok)
+	assert.Equal(t, 150*time.Millisecond, wait)
+
+	_, ok = bucket.take(now.Add(time.Hour), 0)
+	assert.True(t, ok)
+	_, ok = bucket.take(now.Add(time.Hour), 0)
+	assert.True(t, ok)
+	_, ok = bucket.take(now.Add(time.Hour), 0)
+	assert.False(t, ok)
+
+}
+
+func TestRateLimit(t *testing.T) {
+	type request struct {
+		url string
+		key string
+	}
+
+	type args struct {
+		options  RateLimitOptions
+		requests []request
+	}
+
+	type want struct {
+		limited []bool
+	}
+
+	type test struct {
+		name string
+		args args
+		want want
+	}
+
+	tests := []test{
+		{
+			name: "Global bucket",
+			args: args{
+				options: RateLimitOptions{Rate: 1, Burst: 2},
+				requests: []request{
+					{url: "http://a.example.com"},
+					{url: "http://b.example.com"},
+					{url: "http://a.example.com"},
+				},
+			},
+			want: want{
+				limited: []bool{false, false, true},
+			},
+		},
+		{
+			name: "Bucket per host",
+			args: args{
+				options: RateLimitOptions{Rate: 1, Key: KeyByHost},
+				requests: []request{
+					{url: "http://a.example.com"},
+					{url: "http://b.example.com"},
+					{url: "http://a.example.com/path"},
+				},
+			},
+			want: want{
+				limited: []bool{false, false, true},
+			},
+		},
+		{
+			name: "Bucket per context key",
+			args: args{
+				options: RateLimitOptions{Rate: 1, Key: KeyByContext},
+				requests: []request{
+					{url: "http://a.example.com", key: "first"},
+					{url: "http://a.example.com", key: "second"},
+					{url: "http://b.example.com", key: "first"},
+				},
+			},
+			want: want{
+				limited: []bool{false, false, true},
+			},
+		},
+		{
+			name: "No limit",
+			args: args{
+				requests: []request{
+					{url: "http://a.example.com"},
+					{url: "http://a.example.com"},
+				},
+			},
+			want: want{
+				limited: []bool{false, false},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient(
+				WithTransport(
+					RoundTripper(
+						func(req *http.Request) (*http.Response, error) {
+							return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+						},
+					),
+				),
+				WithInterceptors(RateLimit(tc.args.options)),
+			)
+
+			var limited []bool
+			for _, req := range tc.args.requests {
+				ctx := WithRequestKey(context.Background(), req.key)
+
+				_, err := c.Request().Get(ctx, req.url)
+				if err != nil {
+					require.ErrorIs(t, err, ErrRateLimited)
+				}
+
+				limited = append(limited, err != nil)
+			}
+
+			assert.Equal(t, tc.want.limited, limited)
+
+		})
+	}
+
+}
+
+func TestRateLimit_Wait(t *testing.T) {
+	c := NewClient(
+		WithTransport(
+			RoundTripper(
+				func(req *http.Request) (*http.Response, error) {
+					return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+				},
+			),
+		),
+		WithInterceptors(RateLimit(RateLimitOptions{Rate: 20, Wait: true})),
+	)
+
+	started := time.Now()
+
+	for i := 0; i < 3; i++ {
+		_, err := c.Request().Get(context.Background(), "http://localhost:8080")
+		require.NoError(t, err)
+	}
+
+	assert.GreaterOrEqual(t, time.Since(started), 90*time.Millisecond)
+
+	_, err := c.Request().WithTimeout(10*time.Millisecond).Get(context.Background(), "http://localhost:8080")
+	assert.ErrorIs(t, err, ErrRateLimited)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	_, err = c.Request().Get(ctx, "http://localhost:8080")
+	assert.ErrorIs(t, err, context.Canceled)
+
+}
+
+func TestRateLimit_Evict(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	limit := &rateLimit{
+		options: RateLimitOptions{
+			Rate: 1,
+			Key:  KeyByContext,
+			now:  func() time.Time { return now },
+		}.withDefaults(),
+		buckets: make(map[string]*tokenBucket),
+	}
+
+	take := func(key string) error {
+		req, err := http.NewRequestWithContext(
+			WithRequestKey(context.Background(), key),
+			http.MethodGet,
+			"http://localhost:8080",
+			nil,
+		)
+		require.NoError(t, err)
+
+		return limit.wait(req)
+	}
+
+	for _, key := range []string{"first", "second", "third"} {
+		require.NoError(t, take(key))
+	}
+
+	assert.Len(t, limit.buckets, 3)
+
+	now = now.Add(keySweepInterval)
+
+	require.NoError(t, take("first"))
+	assert.Len(t, limit.buckets, 1)
+
+	// Evicted bucket is created again.
+	assert.ErrorIs(t, take("first"), ErrRateLimited)
+
+}
@@ -2,14 +2,25 @@ package request
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
 	"io"
-	"math"
 	"net/http"
 	"slices"
 	"time"
 )
 
-const maxRetries = 3
+const (
+	DefaultRetryMaxAttempts = 4
+	DefaultRetryBaseDelay   = time.Second
+	DefaultRetryMaxDelay    = 30 * time.Second
+)
+
+var (
+	ErrAttemptTimeout = errors.New("attempt timeout exceeded")
+)
 
 var defaultStatusCodes = []int{
 	http.StatusRequestTimeout,
@@ -20,87 +31,366 @@ var defaultStatusCodes = []int{
 	http.StatusGatewayTimeout,
 }
 
+// idempotentMethods are retried by default, see RFC 9110 section 9.2.2.
+var idempotentMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodOptions,
+	http.MethodTrace,
+	http.MethodPut,
+	http.MethodDelete,
+}
+
+// RetryPolicy configures Retry interceptor. Zero values are replaced
+// by defaults, see RetryWithPolicy.
+type RetryPolicy struct {
+	// MaxAttempts is total number of attempts including the first one.
+	MaxAttempts int
+
+	// AttemptTimeout limits time to get Response HEADER of single attempt,
+	// timed out attempt fails with ErrAttemptTimeout and is retried by default.
+	// Request timeout still limits all attempts. Zero means no limit.
+	AttemptTimeout time.Duration
+
+	// MaxElapsedTime limits time spent on all attempts and delays,
+	// retry is not made if its delay exceeds the limit. Zero means no limit.
+	MaxElapsedTime time.Duration
+
+	// Backoff calculates delay between attempts.
+	Backoff Backoff
+
+	// MaxRetryAfter caps delay requested by Response Retry-After,
+	// RateLimit-Reset or X-RateLimit-Reset HEADER, which is used
+	// instead of Backoff. Negative value ignores these HEADERS.
+	MaxRetryAfter time.Duration
+
+	// StatusCodes are Response status codes retried by default predicate.
+	StatusCodes []int
+
+	// ShouldRetry decides whether attempt result is retried
+	// instead of default predicate.
+	ShouldRetry func(res *http.Response, err error) bool
+
+	// Methods are request methods allowed to be retried. Requests
+	// with Idempotency-Key HEADER are retried regardless of method.
+	Methods []string
+
+	// OnRetry is called before delay of every retry with number
+	// of failed attempt, starting from 1, and its result.
+	// Response body is drained after the call.
+	OnRetry func(attempt int, res *http.Response, err error, delay time.Duration)
+
+	// MaxBodyBuffer limits request body without GetBody
+	// kept in memory to be replayed, the rest is spooled to temporary file.
+	MaxBodyBuffer int64
+
+	// MaxBodySpool limits request body spooled to temporary file,
+	// zero means no limit and negative value disables spooling.
+	// Larger body is sent once and is not retried.
+	MaxBodySpool int64
+}
+
+// withDefaults returns policy with zero values replaced by defaults.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultRetryMaxAttempts
+	}
+
+	if p.Backoff == nil {
+		p.Backoff = ExponentialBackoff(DefaultRetryBaseDelay, DefaultRetryMaxDelay)
+	}
+
+	if p.MaxRetryAfter == 0 {
+		p.MaxRetryAfter = DefaultRetryMaxDelay
+	}
+
+	if len(p.StatusCodes) == 0 {
+		p.StatusCodes = defaultStatusCodes
+	}
+
+	if p.ShouldRetry == nil {
+		p.ShouldRetry = retryOn(p.StatusCodes)
+	}
+
+	if len(p.Methods) == 0 {
+		p.Methods = idempotentMethods
+	}
+
+	if p.MaxBodyBuffer <= 0 {
+		p.MaxBodyBuffer = DefaultRetryMaxBodyBuffer
+	}
+
+	return p
+
+}
+
+// retryable checks whether request method or HEADER allow retries.
+func (p RetryPolicy) retryable(req *http.Request) bool {
+	if slices.Contains(p.Methods, req.Method) {
+		return true
+	}
+
+	return req.Header.Get("Idempotency-Key") != "" ||
+		req.Header.Get("X-Idempotency-Key") != ""
+
+}
+
 // Retry interceptor retry request on request failure
 // or on defined Response status codes.
 // By default Retry uses defaultStatusCodes
 func Retry(statusCodes ...int) Interceptor {
-	retryStatusCodes := defaultStatusCodes
+	return RetryWithPolicy(
+		RetryPolicy{
+			StatusCodes: statusCodes,
+		},
+	)
 
-	if len(statusCodes) != 0 {
-		retryStatusCodes = statusCodes
-	}
+}
+
+// RetryWithPolicy interceptor retries request as configured by policy.
+// By default request is attempted up to DefaultRetryMaxAttempts times
+// with exponential backoff, on errors and defaultStatusCodes, and only
+// for idempotent methods. Context cancellation is never retried.
+func RetryWithPolicy(policy RetryPolicy) Interceptor {
+	policy = policy.withDefaults()
 
 	return func(tripper http.RoundTripper) http.RoundTripper {
 		return RoundTripper(
-			func(req *http.Request) (res *http.Response, err error) {
-				var body io.ReadCloser
-				if req.Body != nil {
-					body, err = req.GetBody()
-					if err != nil {
-						return res, err
-					}
+			func(req *http.Request) (*http.Response, error) {
+				if !policy.retryable(req) {
+					return tripper.RoundTrip(req)
 				}
 
-				retries := 0
+				body, err := newRetryBody(req, policy)
+				if err != nil {
+					return nil, err
+				}
 
-				res, err = tripper.RoundTrip(req)
-				for shouldRetry(res, err, retryStatusCodes) && retries < maxRetries {
-					if retries != 0 {
-						sleepWithContext(
-							req.Context(),
-							delay(retries),
-						)
-					}
+				res, err := policy.roundTrip(tripper, req, body)
+				if err != nil || body == nil || body.file == nil {
+					body.close()
 
-					drainBody(res)
+					return res, err
+				}
 
-					if req.Body != nil {
-						req.Body = body
-					}
+				res.Body = cancelOnClose(res.Body, body.close)
 
-					res, err = tripper.RoundTrip(req)
-					retries++
+				return res, nil
 
-				}
+			},
+		)
+	}
+
+}
+
+// roundTrip makes request attempts, each with a copy of request
+// and fresh body, until result is not retried.
+func (p RetryPolicy) roundTrip(
+	tripper http.RoundTripper,
+	req *http.Request,
+	body *retryBody,
+) (res *http.Response, err error) {
+	attempts := attemptsFromContext(req.Context())
+	started := time.Now()
+
+	var delay time.Duration
+
+	for attempt := 1; ; attempt++ {
+		ctx, cancel, stop := attemptContext(req.Context(), p.AttemptTimeout)
+		attemptReq := req.Clone(ctx)
+
+		if body != nil {
+			attemptReq.Body, err = body.open(attempt)
+			if err != nil {
+				cancel()
+
+				return nil, err
+			}
+
+			attemptReq.GetBody = body.getBody
+		}
+
+		res, err = tripper.RoundTrip(attemptReq)
+		if stop() && req.Context().Err() == nil {
+			drainBody(res)
+
+			res, err = nil, attemptTimeoutError(err)
+		}
+
+		retry := attempt < p.MaxAttempts &&
+			req.Context().Err() == nil &&
+			p.ShouldRetry(res, err)
+
+		if retry {
+			delay = p.delay(attempt, delay, res)
+
+			retry = (p.MaxElapsedTime <= 0 || time.Since(started)+delay <= p.MaxElapsedTime) &&
+				!exceedsDeadline(req.Context(), delay)
+		}
+
+		if !retry {
+			attempts.record(statusCode(res), err, 0)
+
+			if err != nil || res == nil {
+				cancel()
 
 				return res, err
+			}
 
-			},
-		)
+			res.Body = cancelOnClose(res.Body, cancel)
+
+			return res, nil
+		}
+
+		if body != nil && body.getBody == nil {
+			attempts.record(statusCode(res), err, 0)
+			drainBody(res)
+			cancel()
+
+			return nil, fmt.Errorf("retry %s %s: %w", req.Method, req.URL.Redacted(), ErrBodyNotReplayable)
+		}
+
+		attempts.record(statusCode(res), err, delay)
+
+		if p.OnRetry != nil {
+			p.OnRetry(attempt, res, err, delay)
+		}
+
+		drainBody(res)
+		cancel()
+
+		if err := sleepWithContext(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+
+}
+
+// attemptContext returns context of single attempt, canceled once timeout
+// is exceeded unless stop is called before. Stop reports whether
+// timeout has been exceeded. Zero timeout means no timeout.
+func attemptContext(
+	parent context.Context,
+	timeout time.Duration,
+) (context.Context, context.CancelFunc, func() bool) {
+	ctx, cancel := context.WithCancelCause(parent)
+
+	if timeout <= 0 {
+		return ctx, func() { cancel(nil) }, func() bool { return false }
+	}
+
+	timer := time.AfterFunc(
+		timeout,
+		func() {
+			cancel(ErrAttemptTimeout)
+		},
+	)
+
+	stop := func() bool {
+		return !timer.Stop() && errors.Is(context.Cause(ctx), ErrAttemptTimeout)
 	}
+
+	return ctx, func() { cancel(nil) }, stop
+
 }
 
-// delay calculates Retry duration
-func delay(retries int) time.Duration {
-	return time.Duration(math.Pow(2, float64(retries))) * time.Second
+func attemptTimeoutError(err error) error {
+	if err == nil {
+		return ErrAttemptTimeout
+	}
+
+	return fmt.Errorf("%w: %w", ErrAttemptTimeout, err)
+
 }
 
-// shouldRetry determine conditions to Retry interceptor
-// by including Response status code
-func shouldRetry(res *http.Response, err error, statusCodes []int) bool {
-	if err != nil {
-		return true
+// exceedsDeadline checks whether context deadline
+// comes before given delay is over.
+func exceedsDeadline(ctx context.Context, delay time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+
+	return ok && time.Until(deadline) < delay
+
+}
+
+// delay returns delay before retry of given attempt,
+// requested by Response HEADER or calculated by Backoff.
+func (p RetryPolicy) delay(
+	attempt int,
+	previous time.Duration,
+	res *http.Response,
+) time.Duration {
+	if p.MaxRetryAfter > 0 {
+		if delay, ok := retryAfter(res, time.Now()); ok {
+			return min(delay, p.MaxRetryAfter)
+		}
 	}
 
-	if res != nil && slices.Contains(statusCodes, res.StatusCode) {
-		return true
+	return p.Backoff(attempt, previous)
+
+}
+
+// retryOn returns default predicate retrying attempt timeout and errors,
+// except context, open circuit and TLS ones, and given Response status codes.
+func retryOn(statusCodes []int) func(*http.Response, error) bool {
+	return func(res *http.Response, err error) bool {
+		if errors.Is(err, ErrAttemptTimeout) {
+			return true
+		}
+
+		if err != nil {
+			return !errors.Is(err, context.Canceled) &&
+				!errors.Is(err, context.DeadlineExceeded) &&
+				!errors.Is(err, ErrCircuitOpen) &&
+				!isTLSError(err)
+		}
+
+		return res != nil && slices.Contains(statusCodes, res.StatusCode)
+	}
+
+}
+
+// isTLSError checks whether error is caused by TLS handshake
+// or certificate verification, which are not fixed by retrying.
+func isTLSError(err error) bool {
+	var (
+		verificationErr *tls.CertificateVerificationError
+		recordHeaderErr tls.RecordHeaderError
+		alertErr        tls.AlertError
+		unknownAuthErr  x509.UnknownAuthorityError
+		hostnameErr     x509.HostnameError
+		invalidErr      x509.CertificateInvalidError
+	)
+
+	return errors.As(err, &verificationErr) ||
+		errors.As(err, &recordHeaderErr) ||
+		errors.As(err, &alertErr) ||
+		errors.As(err, &unknownAuthErr) ||
+		errors.As(err, &hostnameErr) ||
+		errors.As(err, &invalidErr) ||
+		errors.Is(err, ErrCertificatePinMismatch)
+
+}
+
+func statusCode(res *http.Response) int {
+	if res == nil {
+		return 0
 	}
 
-	return false
+	return res.StatusCode
 
 }
 
 // sleepWithContext delays Retry interceptor
 // considering its context and duration
-func sleepWithContext(ctx context.Context, d time.Duration) {
+func sleepWithContext(ctx context.Context, d time.Duration) error {
 	timer := time.NewTimer(d)
+	defer timer.Stop()
 
 	select {
 	case <-ctx.Done():
-		if !timer.Stop() {
-			<-timer.C
-		}
+		return ctx.Err()
 	case <-timer.C:
+		return nil
 	}
 
 }
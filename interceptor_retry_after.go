@@ -0,0 +1,103 @@
+package request
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	RetryAfter          = "Retry-After"
+	RateLimitReset      = "RateLimit-Reset"
+	RateLimitRemaining  = "RateLimit-Remaining"
+	XRateLimitReset     = "X-RateLimit-Reset"
+	XRateLimitRemaining = "X-RateLimit-Remaining"
+
+	// unixTimeThreshold separates X-RateLimit-Reset given as Unix time
+	// from one given in seconds.
+	unixTimeThreshold = 1_000_000_000
+)
+
+// retryAfter returns delay requested by Response Retry-After HEADER,
+// in seconds or as HTTP date, or by rate limit reset HEADER.
+func retryAfter(res *http.Response, now time.Time) (time.Duration, bool) {
+	if res == nil {
+		return 0, false
+	}
+
+	if value := strings.TrimSpace(res.Header.Get(RetryAfter)); value != "" {
+		if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return secondsDuration(seconds), true
+		}
+
+		if date, err := http.ParseTime(value); err == nil {
+			return max(date.Sub(now), 0), true
+		}
+	}
+
+	return rateLimitReset(res.Header, now)
+
+}
+
+// rateLimitReset returns delay until rate limit window is reset
+// by RateLimit-Reset in seconds or X-RateLimit-Reset in seconds or Unix time.
+func rateLimitReset(header http.Header, now time.Time) (time.Duration, bool) {
+	if seconds, ok := headerInt(header, RateLimitReset); ok {
+		return secondsDuration(seconds), true
+	}
+
+	if seconds, ok := headerInt(header, XRateLimitReset); ok {
+		if seconds >= unixTimeThreshold {
+			return max(time.Unix(seconds, 0).Sub(now), 0), true
+		}
+
+		return secondsDuration(seconds), true
+	}
+
+	return 0, false
+
+}
+
+// rateLimitExhausted checks whether RateLimit-Remaining
+// or X-RateLimit-Remaining HEADER reached zero.
+func rateLimitExhausted(header http.Header) bool {
+	if remaining, ok := headerInt(header, RateLimitRemaining); ok {
+		return remaining <= 0
+	}
+
+	if remaining, ok := headerInt(header, XRateLimitRemaining); ok {
+		return remaining <= 0
+	}
+
+	return false
+
+}
+
+func headerInt(header http.Header, key string) (int64, bool) {
+	value := strings.TrimSpace(header.Get(key))
+	if value == "" {
+		return 0, false
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+
+	return n, err == nil
+
+}
+
+// secondsDuration converts seconds to duration,
+// negative seconds are zero and too large ones are capped.
+func secondsDuration(seconds int64) time.Duration {
+	if seconds <= 0 {
+		return 0
+	}
+
+	if seconds > math.MaxInt64/int64(time.Second) {
+		return math.MaxInt64
+	}
+
+	return time.Duration(seconds) * time.Second
+
+}
@@ -0,0 +1,306 @@
+package request
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testHeader returns HEADER with given key and value pairs.
+func testHeader(pairs ...string) http.Header {
+	header := make(http.Header)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		header.Add(pairs[i], pairs[i+1])
+	}
+
+	return header
+
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	type args struct {
+		header http.Header
+	}
+
+	type want struct {
+		delay time.Duration
+		ok    bool
+	}
+
+	type test struct {
+		name string
+		args args
+		want want
+	}
+
+	tests := []test{
+		{
+			name: "No HEADER",
+			args: args{
+				header: http.Header{},
+			},
+			want: want{},
+		},
+		{
+			name: "Retry-After seconds",
+			args: args{
+				header: testHeader(RetryAfter, "120"),
+			},
+			want: want{
+				delay: 2 * time.Minute,
+				ok:    true,
+			},
+		},
+		{
+			name: "Retry-After HTTP date",
+			args: args{
+				header: testHeader(RetryAfter, now.Add(time.Minute).Format(http.TimeFormat)),
+			},
+			want: want{
+				delay: time.Minute,
+				ok:    true,
+			},
+		},
+		{
+			name: "Retry-After date in the past",
+			args: args{
+				header: testHeader(RetryAfter, now.Add(-time.Minute).Format(http.TimeFormat)),
+			},
+			want: want{
+				ok: true,
+			},
+		},
+		{
+			name: "Invalid Retry-After falls back to RateLimit-Reset",
+			args: args{
+				header: testHeader(RetryAfter, "soon", RateLimitReset, "5"),
+			},
+			want: want{
+				delay: 5 * time.Second,
+				ok:    true,
+			},
+		},
+		{
+			name: "Retry-After wins",
+			args: args{
+				header: testHeader(RetryAfter, "1", RateLimitReset, "5"),
+			},
+			want: want{
+				delay: time.Second,
+				ok:    true,
+			},
+		},
+		{
+			name: "X-RateLimit-Reset seconds",
+			args: args{
+				header: testHeader(XRateLimitReset, "30"),
+			},
+			want: want{
+				delay: 30 * time.Second,
+				ok:    true,
+			},
+		},
+		{
+			name: "X-RateLimit-Reset Unix time",
+			args: args{
+				header: testHeader(XRateLimitReset, "1704110445"),
+			},
+			want: want{
+				delay: 45 * time.Second,
+				ok:    true,
+			},
+		},
+		{
+			name: "Too large seconds",
+			args: args{
+				header: testHeader(RetryAfter, "9223372036854775807"),
+			},
+			want: want{
+				delay: time.Duration(1<<63 - 1),
+				ok:    true,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			delay, ok := retryAfter(&http.Response{Header: tc.args.header}, now)
+
+			assert.Equal(t, tc.want.ok, ok)
+			assert.Equal(t, tc.want.delay, delay)
+
+		})
+	}
+
+}
+
+func TestRetryWithPolicy_RetryAfter(t *testing.T) {
+	attempts := 0
+
+	c := NewClient(
+		WithTransport(
+			RoundTripper(
+				func(req *http.Request) (*http.Response, error) {
+					attempts++
+
+					if attempts == 1 {
+						return &http.Response{
+							StatusCode: http.StatusTooManyRequests,
+							Header:     testHeader(RetryAfter, "3600"),
+							Body:       http.NoBody,
+						}, nil
+					}
+
+					return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+				},
+			),
+		),
+		WithInterceptors(
+			RetryWithPolicy(
+				RetryPolicy{
+					Backoff:       ConstantBackoff(time.Hour),
+					MaxRetryAfter: 20 * time.Millisecond,
+				},
+			),
+		),
+	)
+
+	started := time.Now()
+
+	res, err := c.Request().Get(context.Background(), "http://localhost:8080")
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, 2, attempts)
+	assert.GreaterOrEqual(t, time.Since(started), 20*time.Millisecond)
+	assert.Less(t, time.Since(started), time.Minute)
+
+}
+
+func TestServerRateLimit(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	limit := &serverRateLimit{
+		maxWait: time.Minute,
+		now:     func() time.Time { return now },
+		hosts:   make(map[string]time.Time),
+	}
+
+	limit.update("a.example.com", &http.Response{Header: testHeader(RateLimitRemaining, "3", RateLimitReset, "10")})
+	assert.Zero(t, limit.wait("a.example.com"))
+
+	limit.update("a.example.com", &http.Response{Header: testHeader(RateLimitRemaining, "0", RateLimitReset, "10")})
+	assert.Equal(t, 10*time.Second, limit.wait("a.example.com"))
+	assert.Zero(t, limit.wait("b.example.com"))
+
+	limit.update("b.example.com", &http.Response{Header: testHeader(XRateLimitRemaining, "0", XRateLimitReset, "3600")})
+	assert.Equal(t, time.Minute, limit.wait("b.example.com"))
+
+	now = now.Add(4 * time.Second)
+	assert.Equal(t, 6*time.Second, limit.wait("a.example.com"))
+
+	now = now.Add(10 * time.Second)
+	assert.Zero(t, limit.wait("a.example.com"))
+	assert.Empty(t, limit.hosts["a.example.com"])
+
+}
+
+func TestServerRateLimit_Interceptor(t *testing.T) {
+	var remaining string
+
+	c := NewClient(
+		WithTransport(
+			RoundTripper(
+				func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Header:     testHeader(RateLimitRemaining, remaining, RateLimitReset, "3600"),
+						Body:       http.NoBody,
+					}, nil
+				},
+			),
+		),
+		WithInterceptors(ServerRateLimit(30*time.Millisecond)),
+	)
+
+	get := func(ctx context.Context) (time.Duration, error) {
+		started := time.Now()
+
+		_, err := c.Request().Get(ctx, "http://localhost:8080")
+
+		return time.Since(started), err
+	}
+
+	remaining = "0"
+
+	elapsed, err := get(context.Background())
+	require.NoError(t, err)
+	assert.Less(t, elapsed, 30*time.Millisecond)
+
+	remaining = "10"
+
+	elapsed, err = get(context.Background())
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, elapsed, 30*time.Millisecond)
+
+	elapsed, err = get(context.Background())
+	require.NoError(t, err)
+	assert.Less(t, elapsed, 30*time.Millisecond)
+
+	remaining = "0"
+
+	_, err = get(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = get(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+}
+
+func TestServerRateLimit_ClosesBody(t *testing.T) {
+	tripper := ServerRateLimit(time.Minute)(
+		RoundTripper(
+			func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     testHeader(RateLimitRemaining, "0", RateLimitReset, "3600"),
+					Body:       http.NoBody,
+				}, nil
+			},
+		),
+	)
+
+	req, err := http.NewRequest(http.MethodGet, "http://localhost:8080", nil)
+	require.NoError(t, err)
+
+	_, err = tripper.RoundTrip(req)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	var closed atomic.Bool
+
+	req, err = http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		"http://localhost:8080",
+		testClosedBody{Reader: strings.NewReader("body"), closed: &closed},
+	)
+	require.NoError(t, err)
+
+	_, err = tripper.RoundTrip(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, closed.Load())
+
+}
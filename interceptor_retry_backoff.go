@@ -0,0 +1,72 @@
+package request
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff calculates delay before given retry attempt, starting from 1,
+// with previous delay used by decorrelated strategies.
+type Backoff func(attempt int, previous time.Duration) time.Duration
+
+// ConstantBackoff waits the same delay before every retry.
+func ConstantBackoff(delay time.Duration) Backoff {
+	return func(int, time.Duration) time.Duration {
+		return delay
+	}
+
+}
+
+// ExponentialBackoff doubles delay starting from base on every retry,
+// delay never exceeds limit.
+func ExponentialBackoff(base time.Duration, limit time.Duration) Backoff {
+	return func(attempt int, _ time.Duration) time.Duration {
+		return exponentialDelay(base, limit, attempt)
+	}
+
+}
+
+// FullJitterBackoff waits random delay between zero
+// and exponential delay of the attempt.
+func FullJitterBackoff(base time.Duration, limit time.Duration) Backoff {
+	return func(attempt int, _ time.Duration) time.Duration {
+		return randomDelay(0, exponentialDelay(base, limit, attempt))
+	}
+
+}
+
+// DecorrelatedJitterBackoff waits random delay between base
+// and three times previous delay, delay never exceeds limit.
+func DecorrelatedJitterBackoff(base time.Duration, limit time.Duration) Backoff {
+	return func(_ int, previous time.Duration) time.Duration {
+		previous = max(previous, base)
+
+		return min(randomDelay(base, 3*previous), limit)
+	}
+
+}
+
+func exponentialDelay(base time.Duration, limit time.Duration, attempt int) time.Duration {
+	delay := base
+
+	for i := 1; i < attempt && delay < limit; i++ {
+		if delay > limit/2 {
+			return limit
+		}
+
+		delay *= 2
+	}
+
+	return min(delay, limit)
+
+}
+
+// randomDelay returns random delay in [from, to).
+func randomDelay(from time.Duration, to time.Duration) time.Duration {
+	if to <= from {
+		return from
+	}
+
+	return from + rand.N(to-from)
+
+}
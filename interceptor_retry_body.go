@@ -0,0 +1,169 @@
+package request
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+const DefaultRetryMaxBodyBuffer = 1 << 20
+
+var (
+	ErrBodyNotReplayable = errors.New("request body is not replayable")
+)
+
+// retryBody provides fresh request body for every retry attempt.
+// Body without GetBody is spooled to memory and, beyond memory limit,
+// to temporary file, which is removed on close.
+type retryBody struct {
+	getBody func() (io.ReadCloser, error)
+	first   io.ReadCloser
+	file    *os.File
+	once    sync.Once
+}
+
+// newRetryBody prepares request body to be replayed, nil is returned
+// for requests without body.
+func newRetryBody(req *http.Request, policy RetryPolicy) (*retryBody, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if req.GetBody != nil {
+		return &retryBody{
+			getBody: req.GetBody,
+			first:   req.Body,
+		}, nil
+	}
+
+	return spoolBody(req.Body, policy.MaxBodyBuffer, policy.MaxBodySpool)
+
+}
+
+// spoolBody reads body to memory up to bufferLimit bytes and the rest
+// to temporary file up to spoolLimit bytes, zero spoolLimit means no limit
+// and negative one disables file. Body exceeding limits is sent once
+// and is not replayable.
+func spoolBody(body io.ReadCloser, bufferLimit int64, spoolLimit int64) (*retryBody, error) {
+	var buffer bytes.Buffer
+
+	_, err := io.CopyN(&buffer, body, bufferLimit+1)
+	if err == io.EOF {
+		_ = body.Close()
+
+		data := buffer.Bytes()
+		getBody := func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		}
+
+		return &retryBody{
+			getBody: getBody,
+			first:   io.NopCloser(bytes.NewReader(data)),
+		}, nil
+	}
+
+	if err != nil {
+		_ = body.Close()
+
+		return nil, fmt.Errorf("spool request body: %w", err)
+	}
+
+	if spoolLimit < 0 {
+		return &retryBody{
+			first: readCloser(io.MultiReader(&buffer, body), body),
+		}, nil
+	}
+
+	file, err := os.CreateTemp("", "request-body-*")
+	if err != nil {
+		_ = body.Close()
+
+		return nil, fmt.Errorf("spool request body: %w", err)
+	}
+
+	b := &retryBody{file: file}
+
+	size, err := buffer.WriteTo(file)
+	if err == nil {
+		var n int64
+
+		if spoolLimit == 0 {
+			n, err = io.Copy(file, body)
+		} else if n, err = io.CopyN(file, body, max(spoolLimit-size, 0)+1); err == io.EOF {
+			err = nil
+		}
+
+		size += n
+	}
+
+	if err != nil {
+		_ = body.Close()
+		b.close()
+
+		return nil, fmt.Errorf("spool request body: %w", err)
+	}
+
+	if spoolLimit != 0 && size > spoolLimit {
+		b.first = readCloser(io.MultiReader(io.NewSectionReader(file, 0, size), body), body)
+
+		return b, nil
+	}
+
+	_ = body.Close()
+
+	b.getBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(io.NewSectionReader(file, 0, size)), nil
+	}
+	b.first, _ = b.getBody()
+
+	return b, nil
+
+}
+
+// open returns body of given attempt, starting from 1.
+func (b *retryBody) open(attempt int) (io.ReadCloser, error) {
+	if attempt == 1 {
+		return b.first, nil
+	}
+
+	if b.getBody == nil {
+		return nil, ErrBodyNotReplayable
+	}
+
+	body, err := b.getBody()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBodyNotReplayable, err)
+	}
+
+	return body, nil
+
+}
+
+// close removes temporary file body is spooled to.
+func (b *retryBody) close() {
+	if b == nil || b.file == nil {
+		return
+	}
+
+	b.once.Do(
+		func() {
+			_ = b.file.Close()
+			_ = os.Remove(b.file.Name())
+		},
+	)
+
+}
+
+type readCloserBody struct {
+	io.Reader
+	io.Closer
+}
+
+// readCloser joins reader with closer of underlying body.
+func readCloser(reader io.Reader, closer io.Closer) io.ReadCloser {
+	return readCloserBody{Reader: reader, Closer: closer}
+}
@@ -0,0 +1,604 @@
+package request
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testAttempts returns transport replying with given results in order,
+// last result is repeated, and pointer to number of made attempts.
+func testAttempts(results ...any) (http.RoundTripper, *int) {
+	attempts := 0
+
+	return RoundTripper(
+		func(req *http.Request) (*http.Response, error) {
+			result := results[min(attempts, len(results)-1)]
+			attempts++
+
+			if err, ok := result.(error); ok {
+				return nil, err
+			}
+
+			return &http.Response{StatusCode: result.(int), Body: http.NoBody}, nil
+		},
+	), &attempts
+
+}
+
+func TestRetryWithPolicy(t *testing.T) {
+	type args struct {
+		policy  RetryPolicy
+		method  string
+		header  map[string][]string
+		results []any
+	}
+
+	type want struct {
+		attempts int
+		status   int
+		err      error
+	}
+
+	type test struct {
+		name string
+		args args
+		want want
+	}
+
+	tests := []test{
+		{
+			name: "Retries status codes until success",
+			args: args{
+				method:  http.MethodGet,
+				results: []any{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			},
+			want: want{
+				attempts: 3,
+				status:   http.StatusOK,
+			},
+		},
+		{
+			name: "Retries errors",
+			args: args{
+				method:  http.MethodGet,
+				results: []any{errors.New("connection reset"), http.StatusOK},
+			},
+			want: want{
+				attempts: 2,
+				status:   http.StatusOK,
+			},
+		},
+		{
+			name: "Stops after max attempts",
+			args: args{
+				policy:  RetryPolicy{MaxAttempts: 2},
+				method:  http.MethodGet,
+				results: []any{http.StatusServiceUnavailable},
+			},
+			want: want{
+				attempts: 2,
+				status:   http.StatusServiceUnavailable,
+			},
+		},
+		{
+			name: "Does not retry other status codes",
+			args: args{
+				method:  http.MethodGet,
+				results: []any{http.StatusInternalServerError},
+			},
+			want: want{
+				attempts: 1,
+				status:   http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "Custom status codes",
+			args: args{
+				policy:  RetryPolicy{StatusCodes: []int{http.StatusInternalServerError}},
+				method:  http.MethodGet,
+				results: []any{http.StatusInternalServerError, http.StatusOK},
+			},
+			want: want{
+				attempts: 2,
+				status:   http.StatusOK,
+			},
+		},
+		{
+			name: "Custom predicate",
+			args: args{
+				policy: RetryPolicy{
+					ShouldRetry: func(res *http.Response, err error) bool {
+						return res != nil && res.StatusCode == http.StatusConflict
+					},
+				},
+				method:  http.MethodGet,
+				results: []any{http.StatusConflict, http.StatusServiceUnavailable},
+			},
+			want: want{
+				attempts: 2,
+				status:   http.StatusServiceUnavailable,
+			},
+		},
+		{
+			name: "Does not retry POST",
+			args: args{
+				method:  http.MethodPost,
+				results: []any{http.StatusServiceUnavailable, http.StatusOK},
+			},
+			want: want{
+				attempts: 1,
+				status:   http.StatusServiceUnavailable,
+			},
+		},
+		{
+			name: "Retries POST with idempotency key",
+			args: args{
+				method:  http.MethodPost,
+				header:  map[string][]string{"Idempotency-Key": {"1"}},
+				results: []any{http.StatusServiceUnavailable, http.StatusOK},
+			},
+			want: want{
+				attempts: 2,
+				status:   http.StatusOK,
+			},
+		},
+		{
+			name: "Retries allowed methods",
+			args: args{
+				policy:  RetryPolicy{Methods: []string{http.MethodPost}},
+				method:  http.MethodPost,
+				results: []any{http.StatusServiceUnavailable, http.StatusOK},
+			},
+			want: want{
+				attempts: 2,
+				status:   http.StatusOK,
+			},
+		},
+		{
+			name: "Does not retry context cancellation",
+			args: args{
+				method:  http.MethodGet,
+				results: []any{context.Canceled},
+			},
+			want: want{
+				attempts: 1,
+				err:      context.Canceled,
+			},
+		},
+		{
+			name: "Does not retry TLS errors",
+			args: args{
+				method:  http.MethodGet,
+				results: []any{x509.UnknownAuthorityError{}},
+			},
+			want: want{
+				attempts: 1,
+				err:      x509.UnknownAuthorityError{},
+			},
+		},
+		{
+			name: "Stops after max elapsed time",
+			args: args{
+				policy: RetryPolicy{
+					MaxElapsedTime: 5 * time.Millisecond,
+					Backoff:        ConstantBackoff(10 * time.Millisecond),
+				},
+				method:  http.MethodGet,
+				results: []any{http.StatusServiceUnavailable},
+			},
+			want: want{
+				attempts: 1,
+				status:   http.StatusServiceUnavailable,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.args.policy.Backoff == nil {
+				tc.args.policy.Backoff = ConstantBackoff(time.Millisecond)
+			}
+
+			transport, attempts := testAttempts(tc.args.results...)
+
+			c := NewClient(
+				WithTransport(transport),
+				WithInterceptors(RetryWithPolicy(tc.args.policy)),
+			)
+
+			res, err := c.Request().
+				WithHeaders(tc.args.header).
+				Do(context.Background(), tc.args.method, "http://localhost:8080", nil)
+
+			assert.Equal(t, tc.want.attempts, *attempts)
+
+			if tc.want.err != nil {
+				assert.ErrorIs(t, err, tc.want.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want.status, res.StatusCode)
+
+		})
+	}
+
+}
+
+func TestRetryWithPolicy_CanceledDuringDelay(t *testing.T) {
+	transport, attempts := testAttempts(http.StatusServiceUnavailable)
+
+	c := NewClient(
+		WithTransport(transport),
+		WithInterceptors(
+			RetryWithPolicy(
+				RetryPolicy{
+					Backoff: ConstantBackoff(time.Hour),
+				},
+			),
+		),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	_, err := c.Request().WithTimeout(2*time.Hour).Get(ctx, "http://localhost:8080")
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, *attempts)
+
+}
+
+func TestRetryWithPolicy_DeadlineBeforeDelay(t *testing.T) {
+	transport, attempts := testAttempts(http.StatusServiceUnavailable)
+
+	c := NewClient(
+		WithTransport(transport),
+		WithInterceptors(
+			RetryWithPolicy(
+				RetryPolicy{
+					Backoff: ConstantBackoff(time.Hour),
+				},
+			),
+		),
+	)
+
+	started := time.Now()
+
+	res, err := c.Request().WithTimeout(time.Minute).Get(context.Background(), "http://localhost:8080")
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	assert.Equal(t, 1, *attempts)
+	assert.Less(t, time.Since(started), time.Minute)
+
+}
+
+func TestRetryWithPolicy_AttemptTimeout(t *testing.T) {
+	attempts := 0
+
+	c := NewClient(
+		WithTransport(
+			RoundTripper(
+				func(req *http.Request) (*http.Response, error) {
+					attempts++
+
+					if attempts < 3 {
+						<-req.Context().Done()
+
+						return nil, req.Context().Err()
+					}
+
+					return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+				},
+			),
+		),
+		WithInterceptors(
+			RetryWithPolicy(
+				RetryPolicy{
+					AttemptTimeout: 10 * time.Millisecond,
+					Backoff:        ConstantBackoff(time.Millisecond),
+				},
+			),
+		),
+	)
+
+	res, err := c.Request().WithTimeout(time.Minute).Get(context.Background(), "http://localhost:8080")
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, 3, attempts)
+
+	got := res.Attempts()
+	require.Len(t, got.Errors, 3)
+	assert.ErrorIs(t, got.Errors[0], ErrAttemptTimeout)
+	assert.ErrorIs(t, got.Errors[1], ErrAttemptTimeout)
+	assert.NoError(t, got.Errors[2])
+
+	_, err = io.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.NoError(t, res.Body.Close())
+
+}
+
+func TestRetryWithPolicy_AttemptTimeoutWithinRequestTimeout(t *testing.T) {
+	transport := RoundTripper(
+		func(req *http.Request) (*http.Response, error) {
+			<-req.Context().Done()
+
+			return nil, req.Context().Err()
+		},
+	)
+
+	c := NewClient(
+		WithTransport(transport),
+		WithInterceptors(
+			RetryWithPolicy(
+				RetryPolicy{
+					AttemptTimeout: time.Minute,
+				},
+			),
+		),
+	)
+
+	started := time.Now()
+
+	_, err := c.Request().WithTimeout(20*time.Millisecond).Get(context.Background(), "http://localhost:8080")
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NotErrorIs(t, err, ErrAttemptTimeout)
+	assert.Less(t, time.Since(started), time.Minute)
+
+}
+
+func TestRetryWithPolicy_Body(t *testing.T) {
+	content := strings.Repeat("body", 64)
+
+	type args struct {
+		policy RetryPolicy
+		body   func() io.Reader
+	}
+
+	type want struct {
+		attempts int
+		err      error
+	}
+
+	type test struct {
+		name string
+		args args
+		want want
+	}
+
+	tests := []test{
+		{
+			name: "Rewindable body",
+			args: args{
+				body: func() io.Reader { return strings.NewReader(content) },
+			},
+			want: want{
+				attempts: 3,
+			},
+		},
+		{
+			name: "Body spooled to memory",
+			args: args{
+				body: func() io.Reader { return struct{ io.Reader }{strings.NewReader(content)} },
+			},
+			want: want{
+				attempts: 3,
+			},
+		},
+		{
+			name: "Body spooled to file",
+			args: args{
+				policy: RetryPolicy{MaxBodyBuffer: 16},
+				body:   func() io.Reader { return struct{ io.Reader }{strings.NewReader(content)} },
+			},
+			want: want{
+				attempts: 3,
+			},
+		},
+		{
+			name: "Body exceeds spool limit",
+			args: args{
+				policy: RetryPolicy{MaxBodyBuffer: 16, MaxBodySpool: 64},
+				body:   func() io.Reader { return struct{ io.Reader }{strings.NewReader(content)} },
+			},
+			want: want{
+				attempts: 1,
+				err:      ErrBodyNotReplayable,
+			},
+		},
+		{
+			name: "Spooling disabled",
+			args: args{
+				policy: RetryPolicy{MaxBodyBuffer: 16, MaxBodySpool: -1},
+				body:   func() io.Reader { return struct{ io.Reader }{strings.NewReader(content)} },
+			},
+			want: want{
+				attempts: 1,
+				err:      ErrBodyNotReplayable,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("TMPDIR", dir)
+
+			tc.args.policy.Backoff = ConstantBackoff(time.Millisecond)
+
+			var bodies []string
+
+			c := NewClient(
+				WithTransport(
+					RoundTripper(
+						func(req *http.Request) (*http.Response, error) {
+							defer req.Body.Close()
+
+							body, err := io.ReadAll(req.Body)
+							if err != nil {
+								return nil, err
+							}
+
+							bodies = append(bodies, string(body))
+
+							status := http.StatusServiceUnavailable
+							if len(bodies) == 3 {
+								status = http.StatusOK
+							}
+
+							return &http.Response{StatusCode: status, Body: http.NoBody}, nil
+						},
+					),
+				),
+				WithInterceptors(RetryWithPolicy(tc.args.policy)),
+			)
+
+			res, err := c.Request().Put(context.Background(), "http://localhost:8080", tc.args.body())
+
+			require.Len(t, bodies, tc.want.attempts)
+			for _, body := range bodies {
+				assert.Equal(t, content, body)
+			}
+
+			if tc.want.err != nil {
+				assert.ErrorIs(t, err, tc.want.err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusOK, res.StatusCode)
+				assert.NoError(t, res.Body.Close())
+			}
+
+			files, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			assert.Empty(t, files)
+
+		})
+	}
+
+}
+
+func TestRetryWithPolicy_Attempts(t *testing.T) {
+	errReset := errors.New("connection reset")
+
+	transport, _ := testAttempts(errReset, http.StatusServiceUnavailable, http.StatusOK)
+
+	type retry struct {
+		attempt int
+		status  int
+		err     error
+		delay   time.Duration
+	}
+
+	var retries []retry
+
+	c := NewClient(
+		WithTransport(transport),
+		WithInterceptors(
+			RetryWithPolicy(
+				RetryPolicy{
+					Backoff: ConstantBackoff(time.Millisecond),
+					OnRetry: func(attempt int, res *http.Response, err error, delay time.Duration) {
+						retries = append(retries, retry{attempt: attempt, status: statusCode(res), err: err, delay: delay})
+					},
+				},
+			),
+		),
+	)
+
+	res, err := c.Request().Get(context.Background(), "http://localhost:8080")
+	require.NoError(t, err)
+
+	assert.Equal(
+		t,
+		[]retry{
+			{attempt: 1, err: errReset, delay: time.Millisecond},
+			{attempt: 2, status: http.StatusServiceUnavailable, delay: time.Millisecond},
+		},
+		retries,
+	)
+	assert.Equal(
+		t,
+		Attempts{
+			Count:       3,
+			Errors:      []error{errReset, nil, nil},
+			StatusCodes: []int{0, http.StatusServiceUnavailable, http.StatusOK},
+			Delay:       2 * time.Millisecond,
+		},
+		res.Attempts(),
+	)
+
+}
+
+func TestBackoff(t *testing.T) {
+	base := 10 * time.Millisecond
+	limit := 100 * time.Millisecond
+
+	t.Run("Constant", func(t *testing.T) {
+		backoff := ConstantBackoff(base)
+
+		for attempt := 1; attempt < 5; attempt++ {
+			assert.Equal(t, base, backoff(attempt, 0))
+		}
+	})
+
+	t.Run("Exponential", func(t *testing.T) {
+		backoff := ExponentialBackoff(base, limit)
+
+		var got []time.Duration
+		for attempt := 1; attempt < 7; attempt++ {
+			got = append(got, backoff(attempt, 0))
+		}
+
+		assert.Equal(
+			t,
+			[]time.Duration{
+				10 * time.Millisecond,
+				20 * time.Millisecond,
+				40 * time.Millisecond,
+				80 * time.Millisecond,
+				100 * time.Millisecond,
+				100 * time.Millisecond,
+			},
+			got,
+		)
+		assert.Equal(t, time.Duration(1<<62), ExponentialBackoff(time.Second, 1<<62)(100, 0))
+	})
+
+	t.Run("Full jitter", func(t *testing.T) {
+		backoff := FullJitterBackoff(base, limit)
+
+		for attempt := 1; attempt < 100; attempt++ {
+			delay := backoff(attempt, 0)
+
+			assert.GreaterOrEqual(t, delay, time.Duration(0))
+			assert.Less(t, delay, exponentialDelay(base, limit, attempt))
+		}
+	})
+
+	t.Run("Decorrelated jitter", func(t *testing.T) {
+		backoff := DecorrelatedJitterBackoff(base, limit)
+
+		var delay time.Duration
+		for attempt := 1; attempt < 100; attempt++ {
+			previous := max(delay, base)
+			delay = backoff(attempt, delay)
+
+			assert.GreaterOrEqual(t, delay, base)
+			assert.LessOrEqual(t, delay, min(3*previous, limit))
+		}
+	})
+
+}
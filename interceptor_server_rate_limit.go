@@ -0,0 +1,95 @@
+package request
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// serverRateLimit keeps time until which requests to host are delayed.
+type serverRateLimit struct {
+	maxWait time.Duration
+	now     func() time.Time
+
+	mu    sync.Mutex
+	hosts map[string]time.Time
+}
+
+// ServerRateLimit interceptor delays requests to host once its Response
+// reports exhausted rate limit by RateLimit-Remaining or X-RateLimit-Remaining
+// HEADER, until the limit is reset as reported by Retry-After, RateLimit-Reset
+// or X-RateLimit-Reset HEADER. Delay is capped by maxWait and canceled
+// with request context.
+func ServerRateLimit(maxWait time.Duration) Interceptor {
+	limit := &serverRateLimit{
+		maxWait: maxWait,
+		now:     time.Now,
+		hosts:   make(map[string]time.Time),
+	}
+
+	return func(tripper http.RoundTripper) http.RoundTripper {
+		return RoundTripper(
+			func(req *http.Request) (*http.Response, error) {
+				if wait := limit.wait(req.URL.Host); wait > 0 {
+					if err := sleepWithContext(req.Context(), wait); err != nil {
+						closeRequestBody(req)
+
+						return nil, err
+					}
+				}
+
+				res, err := tripper.RoundTrip(req)
+				if err == nil {
+					limit.update(req.URL.Host, res)
+				}
+
+				return res, err
+
+			},
+		)
+	}
+
+}
+
+// wait returns delay before request to host.
+func (l *serverRateLimit) wait(host string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	until, ok := l.hosts[host]
+	if !ok {
+		return 0
+	}
+
+	wait := until.Sub(l.now())
+	if wait <= 0 {
+		delete(l.hosts, host)
+
+		return 0
+	}
+
+	return min(wait, l.maxWait)
+
+}
+
+// update records rate limit reported by host Response.
+func (l *serverRateLimit) update(host string, res *http.Response) {
+	now := l.now()
+
+	reset, ok := time.Duration(0), false
+	if rateLimitExhausted(res.Header) {
+		reset, ok = retryAfter(res, now)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !ok || reset <= 0 {
+		delete(l.hosts, host)
+
+		return
+	}
+
+	l.hosts[host] = now.Add(min(reset, l.maxWait))
+
+}
@@ -0,0 +1,198 @@
+package request
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	ApplicationOctetStream = "application/octet-stream"
+
+	sniffLength = 512
+)
+
+// multipartPart is either form field values or file read from reader or path.
+type multipartPart struct {
+	field    string
+	values   []string
+	filename string
+	reader   io.Reader
+	path     string
+}
+
+// multipartBody builds multipart/form-data body streamed through pipe.
+// Boundary is fixed on creation, so body can be encoded repeatedly
+// with the same Content-Type HEADER.
+type multipartBody struct {
+	boundary string
+	parts    []multipartPart
+}
+
+func newMultipartBody() *multipartBody {
+	return &multipartBody{
+		boundary: multipart.NewWriter(io.Discard).Boundary(),
+	}
+
+}
+
+// clone returns copy of multipart body with the same boundary.
+func (b *multipartBody) clone() *multipartBody {
+	if b == nil {
+		return nil
+	}
+
+	return &multipartBody{
+		boundary: b.boundary,
+		parts:    slices.Clone(b.parts),
+	}
+
+}
+
+// contentType returns multipart/form-data content type with boundary.
+func (b *multipartBody) contentType() string {
+	return mime.FormatMediaType(
+		MultipartFormData,
+		map[string]string{"boundary": b.boundary},
+	)
+
+}
+
+// encode implements bodyEncoder.
+func (b *multipartBody) encode() (io.Reader, string, error) {
+	return b.open(), b.contentType(), nil
+}
+
+// replayable checks whether body can be encoded again,
+// that is it has no parts read from io.Reader.
+func (b *multipartBody) replayable() bool {
+	for _, part := range b.parts {
+		if part.reader != nil {
+			return false
+		}
+	}
+
+	return true
+
+}
+
+// open starts writing parts to pipe and returns its reading end.
+// Write errors are returned from reader.
+func (b *multipartBody) open() io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	go func() {
+		_ = pw.CloseWithError(b.write(pw))
+	}()
+
+	return pr
+
+}
+
+func (b *multipartBody) write(w io.Writer) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(b.boundary); err != nil {
+		return err
+	}
+
+	for _, part := range b.parts {
+		var err error
+
+		switch {
+		case part.path != "":
+			err = writeMultipartFileFromPath(mw, part.field, part.filename, part.path)
+		case part.reader != nil:
+			err = writeMultipartFile(mw, part.field, part.filename, part.reader)
+		default:
+			for _, value := range part.values {
+				if err = mw.WriteField(part.field, value); err != nil {
+					break
+				}
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return mw.Close()
+
+}
+
+func writeMultipartFileFromPath(
+	mw *multipart.Writer,
+	field string,
+	filename string,
+	path string,
+) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return writeMultipartFile(mw, field, filename, file)
+
+}
+
+// writeMultipartFile writes file part with content type detected
+// by file extension or, if unknown, by sniffing its content.
+func writeMultipartFile(
+	mw *multipart.Writer,
+	field string,
+	filename string,
+	reader io.Reader,
+) error {
+	contentType := mime.TypeByExtension(filepath.Ext(filename))
+	if contentType == "" {
+		buffered := bufio.NewReaderSize(reader, sniffLength)
+
+		head, err := buffered.Peek(sniffLength)
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return err
+		}
+
+		contentType = ApplicationOctetStream
+		if len(head) != 0 {
+			contentType = http.DetectContentType(head)
+		}
+
+		reader = buffered
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set(
+		"Content-Disposition",
+		fmt.Sprintf(
+			`form-data; name="%s"; filename="%s"`,
+			escapeQuotes(field),
+			escapeQuotes(filename),
+		),
+	)
+	header.Set(ContentType, contentType)
+
+	w, err := mw.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, reader)
+
+	return err
+
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
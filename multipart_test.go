@@ -0,0 +1,242 @@
+package request
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testMultipartPart struct {
+	field       string
+	filename    string
+	contentType string
+	content     string
+}
+
+// readTestMultipart reads all parts of multipart request body.
+func readTestMultipart(t *testing.T, req *http.Request, body io.Reader) []testMultipartPart {
+	mediaType, params, err := mime.ParseMediaType(req.Header.Get(ContentType))
+	require.NoError(t, err)
+	require.Equal(t, MultipartFormData, mediaType)
+
+	reader := multipart.NewReader(body, params["boundary"])
+
+	var parts []testMultipartPart
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return parts
+		}
+		require.NoError(t, err)
+
+		content, err := io.ReadAll(part)
+		require.NoError(t, err)
+
+		parts = append(
+			parts,
+			testMultipartPart{
+				field:       part.FormName(),
+				filename:    part.FileName(),
+				contentType: part.Header.Get(ContentType),
+				content:     string(content),
+			},
+		)
+	}
+
+}
+
+func TestRequest_Multipart(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"ok":true}`), 0o600))
+
+	type args struct {
+		req func(Request) Request
+	}
+
+	type want struct {
+		parts      []testMultipartPart
+		replayable bool
+		err        bool
+	}
+
+	type test struct {
+		name string
+		args args
+		want want
+	}
+
+	tests := []test{
+		{
+			name: "Fields and files",
+			args: args{
+				req: func(req Request) Request {
+					return req.
+						WithMultipartFormContentType().
+						WithFormField("title", "first", "second").
+						WithFormFile("image", "pixel", bytes.NewReader([]byte("\x89PNG\x0D\x0A\x1A\x0A"))).
+						WithFormFile("notes", `notes "draft".txt`, strings.NewReader("hello"))
+				},
+			},
+			want: want{
+				parts: []testMultipartPart{
+					{field: "title", content: "first"},
+					{field: "title", content: "second"},
+					{field: "image", filename: "pixel", contentType: "image/png", content: "\x89PNG\x0D\x0A\x1A\x0A"},
+					{field: "notes", filename: `notes "draft".txt`, contentType: "text/plain; charset=utf-8", content: "hello"},
+				},
+			},
+		},
+		{
+			name: "File from path",
+			args: args{
+				req: func(req Request) Request {
+					return req.
+						WithFormField("id", "1").
+						WithFormFileFromPath("report", path)
+				},
+			},
+			want: want{
+				parts: []testMultipartPart{
+					{field: "id", content: "1"},
+					{field: "report", filename: "report.json", contentType: "application/json", content: `{"ok":true}`},
+				},
+				replayable: true,
+			},
+		},
+		{
+			name: "Missing file",
+			args: args{
+				req: func(req Request) Request {
+					return req.WithFormFileFromPath("report", filepath.Join(dir, "missing.json"))
+				},
+			},
+			want: want{
+				err: true,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				got   *http.Request
+				parts []testMultipartPart
+			)
+
+			c := NewClient(
+				WithTransport(
+					RoundTripper(
+						func(req *http.Request) (*http.Response, error) {
+							defer req.Body.Close()
+
+							body, err := io.ReadAll(req.Body)
+							if err != nil {
+								return nil, err
+							}
+
+							got = req
+							parts = readTestMultipart(t, req, bytes.NewReader(body))
+
+							return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+						},
+					),
+				),
+			)
+
+			_, err := tc.args.req(c.Request()).Post(context.Background(), "http://localhost:8080", nil)
+			if tc.want.err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, int64(-1), got.ContentLength)
+			assert.Equal(t, tc.want.parts, parts)
+
+			if !tc.want.replayable {
+				assert.Nil(t, got.GetBody)
+				return
+			}
+
+			body, err := got.GetBody()
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.want.parts, readTestMultipart(t, got, body))
+
+		})
+	}
+
+}
+
+func TestRequest_MultipartLargeFile(t *testing.T) {
+	var parts []testMultipartPart
+
+	c := NewClient(
+		WithTransport(
+			RoundTripper(
+				func(req *http.Request) (*http.Response, error) {
+					defer req.Body.Close()
+
+					parts = readTestMultipart(t, req, req.Body)
+
+					return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+				},
+			),
+		),
+	)
+
+	large := bytes.Repeat([]byte{0x00, 0x01}, 1<<20)
+
+	_, err := c.Request().
+		WithFormField("title", "large").
+		WithFormFile("data", "data", bytes.NewReader(large)).
+		Post(context.Background(), "http://localhost:8080", nil)
+
+	require.NoError(t, err)
+	require.Len(t, parts, 2)
+	assert.Equal(t, "large", parts[0].content)
+	assert.Equal(t, ApplicationOctetStream, parts[1].contentType)
+	assert.Equal(t, len(large), len(parts[1].content))
+
+}
+
+func TestRequest_MultipartInvalidRequest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.txt")
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0o600))
+
+	c := NewClient()
+
+	goroutines := runtime.NumGoroutine()
+
+	for i := 0; i < 20; i++ {
+		_, err := c.Request().
+			WithFormField("title", "data").
+			WithFormFileFromPath("data", path).
+			Do(context.Background(), "BAD METHOD", "http://localhost:8080", nil)
+		require.Error(t, err)
+	}
+
+	// Multipart writers are stopped, as encoded bodies are closed.
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); {
+		if runtime.NumGoroutine() <= goroutines {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	assert.LessOrEqual(t, runtime.NumGoroutine(), goroutines)
+
+}
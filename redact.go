@@ -0,0 +1,185 @@
+package request
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveHeaders are always redacted.
+var sensitiveHeaders = []string{
+	Authorization,
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// DefaultSensitiveQuery are query parameters and form fields redacted by default.
+var DefaultSensitiveQuery = []string{
+	"access_token",
+	"api_key",
+	"apikey",
+	"client_secret",
+	"password",
+	"refresh_token",
+	"token",
+}
+
+// DefaultSensitiveFields are JSON fields redacted by default.
+var DefaultSensitiveFields = []string{
+	"access_token",
+	"client_secret",
+	"password",
+	"refresh_token",
+	"secret",
+	"token",
+}
+
+// Redaction configures values redacted by Logging and Dump interceptors
+// in addition to Authorization, Proxy-Authorization, Cookie and Set-Cookie
+// HEADERS, DefaultSensitiveQuery and DefaultSensitiveFields.
+type Redaction struct {
+	// Headers are HEADERS redacted.
+	Headers []string
+
+	// Query are query parameters, and form fields of body, redacted.
+	Query []string
+
+	// Fields are JSON fields of body redacted at any depth.
+	Fields []string
+}
+
+// redactor redacts HEADERS, URL and bodies.
+type redactor struct {
+	headers []string
+	query   []string
+	fields  *regexp.Regexp
+}
+
+func newRedactor(redaction Redaction) *redactor {
+	r := &redactor{
+		query: append(slices.Clone(DefaultSensitiveQuery), redaction.Query...),
+	}
+
+	for _, key := range append(slices.Clone(sensitiveHeaders), redaction.Headers...) {
+		r.headers = append(r.headers, http.CanonicalHeaderKey(key))
+	}
+
+	fields := append(slices.Clone(DefaultSensitiveFields), redaction.Fields...)
+	for i, field := range fields {
+		fields[i] = regexp.QuoteMeta(field)
+	}
+
+	// Value is either string, possibly truncated, or scalar,
+	// nested objects and arrays of sensitive fields are kept.
+	r.fields = regexp.MustCompile(
+		`("(?:` + strings.Join(fields, "|") + `)"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^\s,}\]"{\[]+)`,
+	)
+
+	return r
+
+}
+
+// header returns copy of HEADER with sensitive values redacted.
+func (r *redactor) header(header http.Header) http.Header {
+	header = header.Clone()
+
+	for _, key := range r.headers {
+		if values, ok := header[key]; ok {
+			for i := range values {
+				values[i] = redacted
+			}
+		}
+	}
+
+	return header
+
+}
+
+// url returns URL with password and sensitive query parameters redacted.
+func (r *redactor) url(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+
+	copied := *u
+	copied.RawQuery = r.rawQuery(u.RawQuery)
+
+	return copied.Redacted()
+
+}
+
+// rawQuery redacts sensitive parameters of encoded query keeping its order.
+func (r *redactor) rawQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return rawQuery
+	}
+
+	params := strings.Split(rawQuery, "&")
+	for i, param := range params {
+		key, _, ok := strings.Cut(param, "=")
+		if !ok {
+			continue
+		}
+
+		if name, err := url.QueryUnescape(key); err == nil && r.sensitiveQuery(name) {
+			params[i] = key + "=" + url.QueryEscape(redacted)
+		}
+	}
+
+	return strings.Join(params, "&")
+
+}
+
+func (r *redactor) sensitiveQuery(name string) bool {
+	return slices.ContainsFunc(
+		r.query,
+		func(query string) bool {
+			return strings.EqualFold(query, name)
+		},
+	)
+
+}
+
+// body redacts sensitive JSON fields or form fields of body
+// depending on its content type.
+func (r *redactor) body(contentType string, body []byte) []byte {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch {
+	case mediaType == ApplicationFormUrlencoded:
+		return []byte(r.rawQuery(string(body)))
+	case mediaType == ApplicationJSON || strings.HasSuffix(mediaType, "+json"):
+		return r.fields.ReplaceAll(body, []byte(`$1"`+redacted+`"`))
+	default:
+		return body
+	}
+
+}
+
+// peekBody reads up to limit bytes of body without consuming it, returned
+// body reads the peeked bytes first. Truncated reports body is longer.
+func peekBody(body io.ReadCloser, limit int) (peeked []byte, truncated bool, rest io.ReadCloser, err error) {
+	if body == nil || body == http.NoBody || limit <= 0 {
+		return nil, false, body, nil
+	}
+
+	buffer := make([]byte, limit+1)
+
+	n, err := io.ReadFull(body, buffer)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+
+	rest = readCloser(io.MultiReader(bytes.NewReader(buffer[:n]), body), body)
+
+	return buffer[:min(n, limit)], n > limit, rest, err
+
+}
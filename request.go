@@ -8,7 +8,10 @@ import (
 	"io"
 	"maps"
 	"net/http"
+	"net/textproto"
 	"net/url"
+	"path/filepath"
+	"sync"
 	"time"
 )
 
@@ -27,9 +30,18 @@ const (
 	Basic         = "Basic"
 	Bearer        = "Bearer"
 	JWT           = "JWT"
+
+	UserAgent = "User-Agent"
 )
 
 type Request interface {
+	Do(
+		ctx context.Context,
+		method string,
+		url string,
+		body io.Reader,
+	) (resp *Response, err error)
+
 	Get(
 		ctx context.Context,
 		url string,
@@ -55,6 +67,7 @@ type Request interface {
 	Delete(
 		ctx context.Context,
 		url string,
+		body io.Reader,
 	) (resp *Response, err error)
 
 	Connect(
@@ -65,6 +78,7 @@ type Request interface {
 	Options(
 		ctx context.Context,
 		url string,
+		body io.Reader,
 	) (resp *Response, err error)
 
 	Trace(
@@ -75,6 +89,7 @@ type Request interface {
 	Patch(
 		ctx context.Context,
 		url string,
+		body io.Reader,
 	) (resp *Response, err error)
 
 	URL() *url.URL
@@ -133,14 +148,72 @@ type Request interface {
 	WithTimeout(
 		timeout time.Duration,
 	) Request
+
+	WithProxy(
+		proxyURL string,
+	) Request
+
+	WithPathParam(
+		name string,
+		value string,
+	) Request
+
+	WithPathParams(
+		values map[string]string,
+	) Request
+
+	WithJSONBody(
+		value any,
+	) Request
+
+	WithXMLBody(
+		value any,
+	) Request
+
+	WithFormBody(
+		value any,
+	) Request
+
+	WithFormField(
+		name string,
+		values ...string,
+	) Request
+
+	WithFormFile(
+		field string,
+		filename string,
+		reader io.Reader,
+	) Request
+
+	WithFormFileFromPath(
+		field string,
+		path string,
+	) Request
+
+	WithoutDefaults() Request
+
+	WithoutDefaultHeader(
+		keys ...string,
+	) Request
+
+	WithoutDefaultQuery(
+		names ...string,
+	) Request
+
+	Clone() Request
 }
 
 type request struct {
-	httpReq *http.Request
-	client  *client
-	header  http.Header
-	query   url.Values
-	timeout time.Duration
+	mu         sync.RWMutex
+	client     *client
+	header     http.Header
+	query      url.Values
+	pathParams map[string]string
+	body       bodyEncoder
+	multipart  *multipartBody
+	timeout    time.Duration
+	proxy      string
+	defaults   requestDefaults
 }
 
 func (r *request) do(
@@ -149,16 +222,71 @@ func (r *request) do(
 	url string,
 	body io.Reader,
 ) (resp *Response, err error) {
+	r.mu.RLock()
 	timeout := r.timeout
+	proxy := r.proxy
+	header := r.header.Clone()
+	query := cloneValues(r.query)
+	defaults := r.defaults.clone()
+	pathParams := maps.Clone(r.pathParams)
+	encoder := r.body
+	multipart := r.multipart.clone()
+	r.mu.RUnlock()
+
+	if multipart != nil {
+		encoder = multipart.encode
+	}
+
 	if timeout == 0 {
 		timeout = r.client.timeout
 	}
 
+	if proxy != "" {
+		proxyURL, err := parseProxyURL(proxy)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx = withProxyContext(ctx, proxyURL)
+	}
+
+	attempts := &attemptsRecorder{}
+	ctx = withAttemptsContext(ctx, attempts)
+
+	// Timeout covers the whole exchange including reading
+	// the body, so context is canceled on response body close.
 	ctxWithTimeout, cancel := context.WithTimeout(
 		ctx,
 		timeout,
 	)
-	defer cancel()
+	defer func() {
+		if err != nil {
+			cancel()
+		}
+	}()
+
+	url, err = r.resolveURL(url, pathParams)
+	if err != nil {
+		return nil, err
+	}
+
+	encodeBody := body == nil && encoder != nil
+	if encodeBody {
+		var contentType string
+
+		body, contentType, err = encoder()
+		if err != nil {
+			return nil, fmt.Errorf("encode body: %w", err)
+		}
+
+		if header == nil {
+			header = make(http.Header)
+		}
+
+		if value := header.Get(ContentType); value == "" || value == MultipartFormData {
+			header.Set(ContentType, contentType)
+		}
+	}
 
 	req, err := http.NewRequestWithContext(
 		ctxWithTimeout,
@@ -167,31 +295,198 @@ func (r *request) do(
 		body,
 	)
 	if err != nil {
+		// Encoded body, e.g. multipart pipe, is released as it is never sent.
+		if closer, ok := body.(io.Closer); ok && encodeBody {
+			_ = closer.Close()
+		}
+
 		return nil, err
 	}
 
-	req.Header = r.header
-	req.URL.RawQuery = r.query.Encode()
+	header, query = r.client.applyDefaults(
+		header,
+		query,
+		req.URL.Query(),
+		defaults,
+	)
+
+	if header != nil {
+		req.Header = header
+	}
+
+	if encodeBody && multipart != nil {
+		req.ContentLength = -1
+
+		if multipart.replayable() {
+			req.GetBody = func() (io.ReadCloser, error) {
+				return multipart.open(), nil
+			}
+		}
+	}
+
+	if query := query.Encode(); query != "" {
+		if req.URL.RawQuery != "" {
+			query = req.URL.RawQuery + "&" + query
+		}
 
-	r.httpReq = req
+		req.URL.RawQuery = query
+	}
 
 	res, err := r.client.httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 
+	// Builder does not keep sent request, as it may be sent
+	// concurrently, so it is reported by Response instead.
+	if res.Request == nil {
+		res.Request = req
+	}
+
+	res.Body = cancelOnClose(res.Body, cancel)
+
 	return &Response{
 		Response: res,
+		attempts: attempts,
 	}, err
 
 }
 
+// WithoutDefaults opts request out of all client default
+// HEADER and query parameters.
+func (r *request) WithoutDefaults() Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.defaults.skipAll = true
+
+	return r
+
+}
+
+// WithoutDefaultHeader opts request out of client default HEADER by keys.
+func (r *request) WithoutDefaultHeader(
+	keys ...string,
+) Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, key := range keys {
+		r.defaults.skipHeaders = append(
+			r.defaults.skipHeaders,
+			textproto.CanonicalMIMEHeaderKey(key),
+		)
+	}
+
+	return r
+
+}
+
+// WithoutDefaultQuery opts request out of client default query parameters by names.
+func (r *request) WithoutDefaultQuery(
+	names ...string,
+) Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.defaults.skipQueries = append(r.defaults.skipQueries, names...)
+
+	return r
+
+}
+
+// Clone returns copy of request builder with deep copied HEADER,
+// query and path parameters. Sent request state is not copied.
+func (r *request) Clone() Request {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clone := &request{
+		client:     r.client,
+		header:     r.header.Clone(),
+		query:      cloneValues(r.query),
+		pathParams: maps.Clone(r.pathParams),
+		body:       r.body,
+		multipart:  r.multipart.clone(),
+		timeout:    r.timeout,
+		proxy:      r.proxy,
+		defaults:   r.defaults.clone(),
+	}
+
+	if clone.multipart != nil {
+		clone.body = clone.multipart.encode
+	}
+
+	return clone
+
+}
+
+// cloneValues deep copies url.Values.
+func cloneValues(values url.Values) url.Values {
+	return url.Values(http.Header(values).Clone())
+}
+
+// resolveURL expands URI template with path parameters
+// and resolves result against client base URL.
+func (r *request) resolveURL(
+	rawURL string,
+	pathParams map[string]string,
+) (string, error) {
+	// URL without path parameters is not treated as template,
+	// so literal braces, e.g. JSON in query, are kept.
+	if len(pathParams) != 0 {
+		expanded, err := expandURITemplate(rawURL, pathParams)
+		if err != nil {
+			return "", err
+		}
+
+		rawURL = expanded
+	}
+
+	if r.client == nil || (r.client.baseURL == nil && r.client.baseURLErr == nil) {
+		return rawURL, nil
+	}
+
+	if r.client.baseURLErr != nil {
+		return "", r.client.baseURLErr
+	}
+
+	ref, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	if ref.IsAbs() {
+		return rawURL, nil
+	}
+
+	return r.client.baseURL.ResolveReference(ref).String(), nil
+
+}
+
+// Do method does HTTP request with given method, e.g. PROPFIND or PURGE.
+// Body is sent with any method.
+func (r *request) Do(
+	ctx context.Context,
+	method string,
+	url string,
+	body io.Reader,
+) (resp *Response, err error) {
+	return r.do(
+		ctx,
+		method,
+		url,
+		body,
+	)
+
+}
+
 // Get method does GET HTTP request.
 func (r *request) Get(
 	ctx context.Context,
 	url string,
 ) (resp *Response, err error) {
-	return r.do(
+	return r.Do(
 		ctx,
 		http.MethodGet,
 		url,
@@ -205,7 +500,7 @@ func (r *request) Head(
 	ctx context.Context,
 	url string,
 ) (resp *Response, err error) {
-	return r.do(
+	return r.Do(
 		ctx,
 		http.MethodHead,
 		url,
@@ -220,7 +515,7 @@ func (r *request) Post(
 	url string,
 	body io.Reader,
 ) (resp *Response, err error) {
-	return r.do(
+	return r.Do(
 		ctx,
 		http.MethodPost,
 		url,
@@ -235,7 +530,7 @@ func (r *request) Put(
 	url string,
 	body io.Reader,
 ) (resp *Response, err error) {
-	return r.do(
+	return r.Do(
 		ctx,
 		http.MethodPut,
 		url,
@@ -248,12 +543,13 @@ func (r *request) Put(
 func (r *request) Delete(
 	ctx context.Context,
 	url string,
+	body io.Reader,
 ) (resp *Response, err error) {
-	return r.do(
+	return r.Do(
 		ctx,
 		http.MethodDelete,
 		url,
-		nil,
+		body,
 	)
 
 }
@@ -263,7 +559,7 @@ func (r *request) Connect(
 	ctx context.Context,
 	url string,
 ) (resp *Response, err error) {
-	return r.do(
+	return r.Do(
 		ctx,
 		http.MethodConnect,
 		url,
@@ -276,12 +572,13 @@ func (r *request) Connect(
 func (r *request) Options(
 	ctx context.Context,
 	url string,
+	body io.Reader,
 ) (resp *Response, err error) {
-	return r.do(
+	return r.Do(
 		ctx,
 		http.MethodOptions,
 		url,
-		nil,
+		body,
 	)
 
 }
@@ -291,7 +588,7 @@ func (r *request) Trace(
 	ctx context.Context,
 	url string,
 ) (resp *Response, err error) {
-	return r.do(
+	return r.Do(
 		ctx,
 		http.MethodTrace,
 		url,
@@ -304,42 +601,69 @@ func (r *request) Trace(
 func (r *request) Patch(
 	ctx context.Context,
 	url string,
+	body io.Reader,
 ) (resp *Response, err error) {
-	return r.do(
+	return r.Do(
 		ctx,
 		http.MethodPatch,
 		url,
-		nil,
+		body,
 	)
 
 }
 
-// URL returns request URL.
+// URL returns copy of configured URL, client base URL with configured
+// query, or nil if neither is set. Accessors report configured state
+// only and do not change after send, sent request is Response.Request.
 func (r *request) URL() *url.URL {
-	if r.httpReq != nil {
-		return r.httpReq.URL
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u := &url.URL{}
+	if r.client != nil && r.client.baseURL != nil {
+		copied := *r.client.baseURL
+		u = &copied
+	} else if len(r.query) == 0 {
+		return nil
 	}
 
-	return nil
+	if len(r.query) != 0 {
+		u.RawQuery = r.query.Encode()
+	}
+
+	return u
+
 }
 
-// Header returns request HEADER.
+// Header returns copy of configured HEADER.
 func (r *request) Header() http.Header {
-	if r.httpReq != nil {
-		return r.httpReq.Header
-	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
-	return r.header
+	return r.header.Clone()
 
 }
 
-// Body returns request BODY copy.
+// Body returns configured BODY encoded, body passed to send
+// methods is not part of configuration.
 func (r *request) Body() (io.Reader, error) {
-	if r.httpReq != nil {
-		return r.httpReq.GetBody()
+	r.mu.RLock()
+	encoder := r.body
+	multipart := r.multipart.clone()
+	r.mu.RUnlock()
+
+	if multipart != nil {
+		encoder = multipart.encode
+	}
+
+	if encoder == nil {
+		return nil, ErrNoBody
 	}
 
-	return nil, ErrNoBody
+	body, _, err := encoder()
+
+	return body, err
+
 }
 
 // WithHeader adds given HEADER values by key.
@@ -347,6 +671,9 @@ func (r *request) WithHeader(
 	key string,
 	values ...string,
 ) Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	for _, value := range values {
 		r.header.Add(key, value)
 	}
@@ -359,6 +686,9 @@ func (r *request) WithHeader(
 func (r *request) WithHeaders(
 	values map[string][]string,
 ) Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	maps.Copy(r.header, values)
 
 	return r
@@ -369,6 +699,9 @@ func (r *request) WithHeaders(
 func (r *request) WithContentType(
 	value string,
 ) Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	r.header.Set(
 		ContentType,
 		value,
@@ -380,6 +713,9 @@ func (r *request) WithContentType(
 
 // WithJSONContentType sets application/json content type HEADER.
 func (r *request) WithJSONContentType() Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	r.header.Set(
 		ContentType,
 		ApplicationJSON,
@@ -391,6 +727,9 @@ func (r *request) WithJSONContentType() Request {
 
 // WithXMLContentType sets application/xml content type HEADER.
 func (r *request) WithXMLContentType() Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	r.header.Set(
 		ContentType,
 		ApplicationXML,
@@ -402,6 +741,9 @@ func (r *request) WithXMLContentType() Request {
 
 // WithFormContentType sets application/x-www-form-urlencoded content type HEADER.
 func (r *request) WithFormContentType() Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	r.header.Set(
 		ContentType,
 		ApplicationFormUrlencoded,
@@ -413,6 +755,9 @@ func (r *request) WithFormContentType() Request {
 
 // WithMultipartFormContentType sets multipart/form-data content type HEADER.
 func (r *request) WithMultipartFormContentType() Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	r.header.Set(
 		ContentType,
 		MultipartFormData,
@@ -426,6 +771,9 @@ func (r *request) WithMultipartFormContentType() Request {
 func (r *request) WithAuth(
 	values ...string,
 ) Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	for _, value := range values {
 		r.header.Add(Authorization, value)
 	}
@@ -439,6 +787,9 @@ func (r *request) WithBasicAuth(
 	username,
 	password string,
 ) Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	auth := fmt.Sprintf(
 		"%s %s",
 		Basic,
@@ -457,6 +808,9 @@ func (r *request) WithBasicAuth(
 func (r *request) WithBearerAuth(
 	value string,
 ) Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	r.header.Add(
 		Authorization,
 		fmt.Sprintf(
@@ -474,6 +828,9 @@ func (r *request) WithBearerAuth(
 func (r *request) WithJWTAuth(
 	value string,
 ) Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	r.header.Add(
 		Authorization,
 		fmt.Sprintf(
@@ -492,6 +849,9 @@ func (r *request) WithQuery(
 	name string,
 	values ...string,
 ) Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	r.query[name] = append(r.query[name], values...)
 	return r
 
@@ -501,6 +861,9 @@ func (r *request) WithQuery(
 func (r *request) WithQueries(
 	values map[string][]string,
 ) Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	maps.Copy(r.query, values)
 
 	return r
@@ -512,8 +875,196 @@ func (r *request) WithQueries(
 func (r *request) WithTimeout(
 	timeout time.Duration,
 ) Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	r.timeout = timeout
 
 	return r
 
 }
+
+// WithProxy overrides client proxy for the request.
+// Supported schemes are http, https, socks5 and socks5h.
+// Request proxy is used even if host matches client NO_PROXY rules.
+func (r *request) WithProxy(
+	proxyURL string,
+) Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.proxy = proxyURL
+
+	return r
+
+}
+
+// WithPathParam sets URI template variable value. Request URL is
+// expanded as RFC 6570 URI template, e.g. "/users/{id}/posts{?page,limit}".
+func (r *request) WithPathParam(
+	name string,
+	value string,
+) Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.pathParams == nil {
+		r.pathParams = make(map[string]string)
+	}
+
+	r.pathParams[name] = value
+
+	return r
+
+}
+
+// WithPathParams sets URI template variables values.
+func (r *request) WithPathParams(
+	values map[string]string,
+) Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.pathParams == nil {
+		r.pathParams = make(map[string]string, len(values))
+	}
+
+	maps.Copy(r.pathParams, values)
+
+	return r
+
+}
+
+// WithJSONBody sets value encoded as JSON to be request body
+// and application/json content type HEADER, unless it is already set.
+// Value is encoded on send, encoding error is returned from send method.
+// Body argument of send method takes precedence over it.
+func (r *request) WithJSONBody(
+	value any,
+) Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.body = jsonBody(value)
+	r.multipart = nil
+
+	return r
+
+}
+
+// WithXMLBody sets value encoded as XML to be request body
+// and application/xml content type HEADER, unless it is already set.
+// Value is encoded on send, encoding error is returned from send method.
+// Body argument of send method takes precedence over it.
+func (r *request) WithXMLBody(
+	value any,
+) Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.body = xmlBody(value)
+	r.multipart = nil
+
+	return r
+
+}
+
+// WithFormBody sets url.Values, map or struct with form tags encoded
+// as form to be request body and application/x-www-form-urlencoded
+// content type HEADER, unless it is already set. Value is encoded on send,
+// encoding error is returned from send method.
+// Body argument of send method takes precedence over it.
+func (r *request) WithFormBody(
+	value any,
+) Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.body = formBody(value)
+	r.multipart = nil
+
+	return r
+
+}
+
+// multipartBody returns request multipart body,
+// making it request body on first use.
+func (r *request) multipartBody() *multipartBody {
+	if r.multipart == nil {
+		r.multipart = newMultipartBody()
+	}
+
+	r.body = r.multipart.encode
+
+	return r.multipart
+
+}
+
+// WithFormField adds multipart form field values.
+func (r *request) WithFormField(
+	name string,
+	values ...string,
+) Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	body := r.multipartBody()
+	body.parts = append(
+		body.parts,
+		multipartPart{
+			field:  name,
+			values: values,
+		},
+	)
+
+	return r
+
+}
+
+// WithFormFile adds multipart form file read from reader. Reader is
+// streamed on send, so request with it can not be sent twice.
+// Part content type is detected by filename extension or content.
+func (r *request) WithFormFile(
+	field string,
+	filename string,
+	reader io.Reader,
+) Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	body := r.multipartBody()
+	body.parts = append(
+		body.parts,
+		multipartPart{
+			field:    field,
+			filename: filename,
+			reader:   reader,
+		},
+	)
+
+	return r
+
+}
+
+// WithFormFileFromPath adds multipart form file read from path on send.
+// Part content type is detected by file extension or content.
+func (r *request) WithFormFileFromPath(
+	field string,
+	path string,
+) Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	body := r.multipartBody()
+	body.parts = append(
+		body.parts,
+		multipartPart{
+			field:    field,
+			filename: filepath.Base(path),
+			path:     path,
+		},
+	)
+
+	return r
+
+}
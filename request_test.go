@@ -6,13 +6,43 @@ import (
 	"encoding/base64"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 )
 
+// assertResponse compares responses by status code and body content,
+// since response body is wrapped to cancel request context on close.
+func assertResponse(t *testing.T, want *Response, got *Response) {
+	t.Helper()
+
+	if want == nil {
+		assert.Nil(t, got)
+		return
+	}
+
+	if !assert.NotNil(t, got) {
+		return
+	}
+
+	assert.Equal(t, want.StatusCode, got.StatusCode)
+
+	wantBody, err := io.ReadAll(want.Body)
+	assert.NoError(t, err)
+
+	gotBody, err := io.ReadAll(got.Body)
+	assert.NoError(t, err)
+
+	assert.Equal(t, wantBody, gotBody)
+	assert.NoError(t, got.Body.Close())
+
+}
+
 func TestRequest_Do(t *testing.T) {
 	type args struct {
 		ctx    context.Context
@@ -133,7 +163,7 @@ func TestRequest_Do(t *testing.T) {
 				tc.args.body,
 			)
 
-			assert.Equal(t, tc.want.res, res)
+			assertResponse(t, tc.want.res, res)
 			assert.Equal(t, tc.want.err, err)
 
 		})
@@ -211,7 +241,7 @@ func TestRequest_Get(t *testing.T) {
 				tc.args.url,
 			)
 
-			assert.Equal(t, tc.want.res, res)
+			assertResponse(t, tc.want.res, res)
 			assert.Equal(t, tc.want.err, err)
 
 		})
@@ -290,7 +320,7 @@ func TestRequest_Head(t *testing.T) {
 				tc.args.url,
 			)
 
-			assert.Equal(t, tc.want.res, res)
+			assertResponse(t, tc.want.res, res)
 			assert.Equal(t, tc.want.err, err)
 
 		})
@@ -371,7 +401,7 @@ func TestRequest_Post(t *testing.T) {
 				tc.args.body,
 			)
 
-			assert.Equal(t, tc.want.res, res)
+			assertResponse(t, tc.want.res, res)
 			assert.Equal(t, tc.want.err, err)
 
 		})
@@ -452,7 +482,7 @@ func TestRequest_Put(t *testing.T) {
 				tc.args.body,
 			)
 
-			assert.Equal(t, tc.want.res, res)
+			assertResponse(t, tc.want.res, res)
 			assert.Equal(t, tc.want.err, err)
 
 		})
@@ -462,8 +492,9 @@ func TestRequest_Put(t *testing.T) {
 
 func TestRequest_Delete(t *testing.T) {
 	type args struct {
-		ctx context.Context
-		url string
+		ctx  context.Context
+		url  string
+		body io.Reader
 	}
 
 	type want struct {
@@ -488,8 +519,9 @@ func TestRequest_Delete(t *testing.T) {
 		{
 			name: "Success response",
 			args: args{
-				ctx: context.Background(),
-				url: "http://localhost:8080",
+				ctx:  context.Background(),
+				url:  "http://localhost:8080",
+				body: bytes.NewReader([]byte(`{"op":"remove"}`)),
 			},
 			want: want{
 				res: &Response{
@@ -529,9 +561,10 @@ func TestRequest_Delete(t *testing.T) {
 			res, err := r.Delete(
 				tc.args.ctx,
 				tc.args.url,
+				tc.args.body,
 			)
 
-			assert.Equal(t, tc.want.res, res)
+			assertResponse(t, tc.want.res, res)
 			assert.Equal(t, tc.want.err, err)
 
 		})
@@ -609,7 +642,7 @@ func TestRequest_Connect(t *testing.T) {
 				tc.args.url,
 			)
 
-			assert.Equal(t, tc.want.res, res)
+			assertResponse(t, tc.want.res, res)
 			assert.Equal(t, tc.want.err, err)
 
 		})
@@ -619,8 +652,9 @@ func TestRequest_Connect(t *testing.T) {
 
 func TestRequest_Options(t *testing.T) {
 	type args struct {
-		ctx context.Context
-		url string
+		ctx  context.Context
+		url  string
+		body io.Reader
 	}
 
 	type want struct {
@@ -645,8 +679,9 @@ func TestRequest_Options(t *testing.T) {
 		{
 			name: "Success response",
 			args: args{
-				ctx: context.Background(),
-				url: "http://localhost:8080",
+				ctx:  context.Background(),
+				url:  "http://localhost:8080",
+				body: bytes.NewReader([]byte(`{"origin":"example.com"}`)),
 			},
 			want: want{
 				res: &Response{
@@ -686,9 +721,10 @@ func TestRequest_Options(t *testing.T) {
 			res, err := r.Options(
 				tc.args.ctx,
 				tc.args.url,
+				tc.args.body,
 			)
 
-			assert.Equal(t, tc.want.res, res)
+			assertResponse(t, tc.want.res, res)
 			assert.Equal(t, tc.want.err, err)
 
 		})
@@ -767,7 +803,7 @@ func TestRequest_Trace(t *testing.T) {
 				tc.args.url,
 			)
 
-			assert.Equal(t, tc.want.res, res)
+			assertResponse(t, tc.want.res, res)
 			assert.Equal(t, tc.want.err, err)
 
 		})
@@ -777,8 +813,9 @@ func TestRequest_Trace(t *testing.T) {
 
 func TestRequest_Patch(t *testing.T) {
 	type args struct {
-		ctx context.Context
-		url string
+		ctx  context.Context
+		url  string
+		body io.Reader
 	}
 
 	type want struct {
@@ -803,8 +840,9 @@ func TestRequest_Patch(t *testing.T) {
 		{
 			name: "Success response",
 			args: args{
-				ctx: context.Background(),
-				url: "http://localhost:8080",
+				ctx:  context.Background(),
+				url:  "http://localhost:8080",
+				body: bytes.NewReader([]byte(`{"op":"remove"}`)),
 			},
 			want: want{
 				res: &Response{
@@ -844,9 +882,10 @@ func TestRequest_Patch(t *testing.T) {
 			res, err := r.Patch(
 				tc.args.ctx,
 				tc.args.url,
+				tc.args.body,
 			)
 
-			assert.Equal(t, tc.want.res, res)
+			assertResponse(t, tc.want.res, res)
 			assert.Equal(t, tc.want.err, err)
 
 		})
@@ -859,7 +898,8 @@ func TestRequest_URL(t *testing.T) {
 	}
 
 	type depends struct {
-		httpRequest *http.Request
+		options []func(*client)
+		query   url.Values
 	}
 
 	type test struct {
@@ -870,47 +910,44 @@ func TestRequest_URL(t *testing.T) {
 
 	tests := []test{
 		{
-			name: "Nil request",
+			name: "Nothing configured",
 			want: want{
 				url: nil,
 			},
-			depends: depends{
-				httpRequest: nil,
-			},
 		},
 		{
-			name: "Nil url",
+			name: "Configured query",
 			want: want{
-				url: nil,
+				url: &url.URL{
+					RawQuery: "page=1",
+				},
 			},
 			depends: depends{
-				httpRequest: &http.Request{
-					URL: nil,
-				},
+				query: url.Values{"page": {"1"}},
 			},
 		},
 		{
-			name: "Not nil url",
+			name: "Base URL with configured query",
 			want: want{
 				url: &url.URL{
-					Scheme: "https",
+					Scheme:   "https",
+					Host:     "example.com",
+					Path:     "/v1/",
+					RawQuery: "page=1",
 				},
 			},
 			depends: depends{
-				httpRequest: &http.Request{
-					URL: &url.URL{
-						Scheme: "https",
-					},
-				},
+				options: []func(*client){WithBaseURL("https://example.com/v1")},
+				query:   url.Values{"page": {"1"}},
 			},
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			req := request{
-				httpReq: tc.depends.httpRequest,
-			}
+			req := NewClient(tc.depends.options...).
+				Request().
+				WithQueries(tc.depends.query)
 
 			assert.Equal(t, tc.want.url, req.URL())
 
@@ -921,11 +958,12 @@ func TestRequest_URL(t *testing.T) {
 
 func TestRequest_Body(t *testing.T) {
 	type want struct {
-		body io.ReadCloser
+		body string
+		err  error
 	}
 
 	type depends struct {
-		httpRequest *http.Request
+		req func(Request) Request
 	}
 
 	type test struct {
@@ -936,33 +974,19 @@ func TestRequest_Body(t *testing.T) {
 
 	tests := []test{
 		{
-			name: "Nil request",
-			want: want{
-				body: nil,
-			},
-			depends: depends{
-				httpRequest: nil,
-			},
-		},
-		{
-			name: "Nil body",
+			name: "Nothing configured",
 			want: want{
-				body: nil,
-			},
-			depends: depends{
-				httpRequest: &http.Request{
-					URL: nil,
-				},
+				err: ErrNoBody,
 			},
 		},
 		{
-			name: "Not nil body",
+			name: "Configured body",
 			want: want{
-				body: io.NopCloser(bytes.NewBuffer([]byte(`Sample`))),
+				body: `{"id":1}`,
 			},
 			depends: depends{
-				httpRequest: &http.Request{
-					Body: io.NopCloser(bytes.NewBuffer([]byte(`Sample`))),
+				req: func(req Request) Request {
+					return req.WithJSONBody(map[string]int{"id": 1})
 				},
 			},
 		},
@@ -970,11 +994,19 @@ func TestRequest_Body(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			req := request{
-				httpReq: tc.depends.httpRequest,
+			req := NewClient().Request()
+			if tc.depends.req != nil {
+				req = tc.depends.req(req)
 			}
 
-			assert.Equal(t, tc.want.body, req.Body())
+			body, err := req.Body()
+			assert.Equal(t, tc.want.err, err)
+
+			if tc.want.err == nil {
+				b, err := io.ReadAll(body)
+				require.NoError(t, err)
+				assert.Equal(t, tc.want.body, string(b))
+			}
 
 		})
 	}
@@ -982,68 +1014,38 @@ func TestRequest_Body(t *testing.T) {
 }
 
 func TestRequest_Header(t *testing.T) {
-	type want struct {
-		header http.Header
-	}
-
-	type depends struct {
-		httpRequest *http.Request
-	}
-
-	type test struct {
-		name    string
-		want    want
-		depends depends
-	}
-
-	tests := []test{
-		{
-			name: "Nil request",
-			want: want{
-				header: nil,
-			},
-			depends: depends{
-				httpRequest: nil,
-			},
-		},
-		{
-			name: "Nil header",
-			want: want{
-				header: nil,
-			},
-			depends: depends{
-				httpRequest: &http.Request{
-					URL: nil,
-				},
-			},
-		},
-		{
-			name: "Not nil header",
-			want: want{
-				header: http.Header{
-					http.CanonicalHeaderKey("Content-Type"): {"text/plain; charset=utf-8"},
-				},
-			},
-			depends: depends{
-				httpRequest: &http.Request{
-					Header: http.Header{
-						http.CanonicalHeaderKey("Content-Type"): {"text/plain; charset=utf-8"},
-					},
-				},
-			},
-		},
-	}
-
-	for _, tc := range tests {
-		t.Run(tc.name, func(t *testing.T) {
-			req := request{
-				httpReq: tc.depends.httpRequest,
-			}
-
-			assert.Equal(t, tc.want.header, req.Header())
-
-		})
-	}
+	c := NewClient(
+		WithTransport(
+			RoundTripper(
+				func(req *http.Request) (*http.Response, error) {
+					return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+				},
+			),
+		),
+		WithUserAgent("agent"),
+	)
+
+	req := c.Request().WithHeader(ContentType, "text/plain; charset=utf-8")
+
+	assert.Equal(
+		t,
+		http.Header{ContentType: {"text/plain; charset=utf-8"}},
+		req.Header(),
+	)
+
+	res, err := req.Get(context.Background(), "http://localhost:8080?page=1")
+	require.NoError(t, err)
+
+	// Accessors keep configured state, sent request is reported by Response.
+	assert.Equal(
+		t,
+		http.Header{ContentType: {"text/plain; charset=utf-8"}},
+		req.Header(),
+	)
+	assert.Nil(t, req.URL())
+
+	assert.Equal(t, "agent", res.Request.Header.Get(UserAgent))
+	assert.Equal(t, "http://localhost:8080?page=1", res.Request.URL.String())
 
 }
 
@@ -1921,7 +1923,7 @@ func TestRequest_WithJWTAuth(t *testing.T) {
 func TestRequest_WithQuery(t *testing.T) {
 	type args struct {
 		key    string
-		values []any
+		values []string
 	}
 
 	type want struct {
@@ -1944,7 +1946,7 @@ func TestRequest_WithQuery(t *testing.T) {
 			name: "Without collision",
 			args: args{
 				key:    "key",
-				values: []any{"value 1", "value 2", "value 3", 4, true, false},
+				values: []string{"value 1", "value 2", "value 3", "4", "true", "false"},
 			},
 			want: want{
 				req: &request{
@@ -1961,7 +1963,7 @@ func TestRequest_WithQuery(t *testing.T) {
 			name: "With collision",
 			args: args{
 				key:    "key",
-				values: []any{"value 4", "value 5", "value 6", 7, true, false},
+				values: []string{"value 4", "value 5", "value 6", "7", "true", "false"},
 			},
 			want: want{
 				req: &request{
@@ -2121,3 +2123,657 @@ func TestRequest_WithTimeout(t *testing.T) {
 		})
 	}
 }
+
+func TestRequest_WithPathParam(t *testing.T) {
+	type args struct {
+		name  string
+		value string
+	}
+
+	type want struct {
+		req *request
+	}
+
+	type depends struct {
+		pathParams map[string]string
+	}
+
+	type test struct {
+		name    string
+		args    args
+		want    want
+		depends depends
+	}
+
+	tests := []test{
+		{
+			name: "Without params",
+			args: args{
+				name:  "id",
+				value: "1",
+			},
+			want: want{
+				req: &request{
+					pathParams: map[string]string{"id": "1"},
+				},
+			},
+		},
+		{
+			name: "With collision",
+			args: args{
+				name:  "id",
+				value: "2",
+			},
+			want: want{
+				req: &request{
+					pathParams: map[string]string{"id": "2", "page": "1"},
+				},
+			},
+			depends: depends{
+				pathParams: map[string]string{"id": "1", "page": "1"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := &request{
+				pathParams: tc.depends.pathParams,
+			}
+
+			assert.Equal(
+				t,
+				tc.want.req,
+				req.WithPathParam(tc.args.name, tc.args.value),
+			)
+
+		})
+	}
+
+}
+
+func TestRequest_WithPathParams(t *testing.T) {
+	type args struct {
+		values map[string]string
+	}
+
+	type want struct {
+		req *request
+	}
+
+	type depends struct {
+		pathParams map[string]string
+	}
+
+	type test struct {
+		name    string
+		args    args
+		want    want
+		depends depends
+	}
+
+	tests := []test{
+		{
+			name: "Without params",
+			args: args{
+				values: map[string]string{"id": "1"},
+			},
+			want: want{
+				req: &request{
+					pathParams: map[string]string{"id": "1"},
+				},
+			},
+		},
+		{
+			name: "With collision",
+			args: args{
+				values: map[string]string{"id": "2", "limit": "10"},
+			},
+			want: want{
+				req: &request{
+					pathParams: map[string]string{"id": "2", "page": "1", "limit": "10"},
+				},
+			},
+			depends: depends{
+				pathParams: map[string]string{"id": "1", "page": "1"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := &request{
+				pathParams: tc.depends.pathParams,
+			}
+
+			assert.Equal(
+				t,
+				tc.want.req,
+				req.WithPathParams(tc.args.values),
+			)
+
+		})
+	}
+
+}
+
+func TestRequest_ResolveURL(t *testing.T) {
+	type args struct {
+		url string
+	}
+
+	type want struct {
+		url string
+		err bool
+	}
+
+	type depends struct {
+		options    []func(*client)
+		pathParams map[string]string
+		query      url.Values
+	}
+
+	type test struct {
+		name    string
+		args    args
+		want    want
+		depends depends
+	}
+
+	tests := []test{
+		{
+			name: "Without base URL",
+			args: args{
+				url: "https://example.com/users",
+			},
+			want: want{
+				url: "https://example.com/users",
+			},
+		},
+		{
+			name: "Relative to base URL",
+			args: args{
+				url: "users",
+			},
+			want: want{
+				url: "https://example.com/v1/users",
+			},
+			depends: depends{
+				options: []func(*client){WithBaseURL("https://example.com/v1")},
+			},
+		},
+		{
+			name: "Absolute path against base URL",
+			args: args{
+				url: "/users",
+			},
+			want: want{
+				url: "https://example.com/users",
+			},
+			depends: depends{
+				options: []func(*client){WithBaseURL("https://example.com/v1/")},
+			},
+		},
+		{
+			name: "Absolute URL ignores base URL",
+			args: args{
+				url: "https://other.com/users",
+			},
+			want: want{
+				url: "https://other.com/users",
+			},
+			depends: depends{
+				options: []func(*client){WithBaseURL("https://example.com/v1")},
+			},
+		},
+		{
+			name: "Template with query",
+			args: args{
+				url: "users/{id}/posts{?page,limit}",
+			},
+			want: want{
+				url: "https://example.com/v1/users/a%2Fb/posts?page=2&sort=desc",
+			},
+			depends: depends{
+				options:    []func(*client){WithBaseURL("https://example.com/v1")},
+				pathParams: map[string]string{"id": "a/b", "page": "2"},
+				query:      url.Values{"sort": {"desc"}},
+			},
+		},
+		{
+			name: "Braces without path params",
+			args: args{
+				url: `search?q={"a":1}`,
+			},
+			want: want{
+				url: `https://example.com/v1/search?q={"a":1}&limit=5`,
+			},
+			depends: depends{
+				options: []func(*client){WithBaseURL("https://example.com/v1")},
+				query:   url.Values{"limit": {"5"}},
+			},
+		},
+		{
+			name: "Invalid template",
+			args: args{
+				url: "users/{id",
+			},
+			want: want{
+				err: true,
+			},
+			depends: depends{
+				pathParams: map[string]string{"id": "1"},
+			},
+		},
+		{
+			name: "Invalid base URL",
+			args: args{
+				url: "users",
+			},
+			want: want{
+				err: true,
+			},
+			depends: depends{
+				options: []func(*client){WithBaseURL("/v1")},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var reqURL string
+
+			c := NewClient(
+				append(
+					tc.depends.options,
+					WithTransport(
+						RoundTripper(
+							func(req *http.Request) (*http.Response, error) {
+								reqURL = req.URL.String()
+								return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+							},
+						),
+					),
+				)...,
+			)
+
+			req := c.Request().
+				WithPathParams(tc.depends.pathParams).
+				WithQueries(tc.depends.query)
+
+			_, err := req.Get(context.Background(), tc.args.url)
+			if tc.want.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want.url, reqURL)
+
+		})
+	}
+
+}
+
+func TestRequest_WithBody(t *testing.T) {
+	type args struct {
+		req  func(Request) Request
+		body io.Reader
+	}
+
+	type want struct {
+		body          string
+		contentType   string
+		contentLength int64
+		err           bool
+	}
+
+	type test struct {
+		name string
+		args args
+		want want
+	}
+
+	tests := []test{
+		{
+			name: "WithJSONBody",
+			args: args{
+				req: func(req Request) Request {
+					return req.WithJSONBody(map[string]int{"id": 1})
+				},
+			},
+			want: want{
+				body:          `{"id":1}`,
+				contentType:   ApplicationJSON,
+				contentLength: 8,
+			},
+		},
+		{
+			name: "WithJSONBody keeps content type",
+			args: args{
+				req: func(req Request) Request {
+					return req.
+						WithContentType("application/merge-patch+json").
+						WithJSONBody(map[string]int{"id": 1})
+				},
+			},
+			want: want{
+				body:          `{"id":1}`,
+				contentType:   "application/merge-patch+json",
+				contentLength: 8,
+			},
+		},
+		{
+			name: "WithXMLBody",
+			args: args{
+				req: func(req Request) Request {
+					return req.WithXMLBody(
+						struct {
+							XMLName struct{} `xml:"todo"`
+							ID      int      `xml:"id"`
+						}{ID: 1},
+					)
+				},
+			},
+			want: want{
+				body:          `<todo><id>1</id></todo>`,
+				contentType:   ApplicationXML,
+				contentLength: 23,
+			},
+		},
+		{
+			name: "WithFormBody",
+			args: args{
+				req: func(req Request) Request {
+					return req.WithFormBody(url.Values{"id": {"1"}, "name": {"a b"}})
+				},
+			},
+			want: want{
+				body:          `id=1&name=a+b`,
+				contentType:   ApplicationFormUrlencoded,
+				contentLength: 13,
+			},
+		},
+		{
+			name: "Body argument takes precedence",
+			args: args{
+				req: func(req Request) Request {
+					return req.WithJSONBody(map[string]int{"id": 1})
+				},
+				body: bytes.NewReader([]byte(`raw`)),
+			},
+			want: want{
+				body:          `raw`,
+				contentLength: 3,
+			},
+		},
+		{
+			name: "Encoding error",
+			args: args{
+				req: func(req Request) Request {
+					return req.WithJSONBody(func() {})
+				},
+			},
+			want: want{
+				err: true,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got *http.Request
+
+			c := NewClient(
+				WithTransport(
+					RoundTripper(
+						func(req *http.Request) (*http.Response, error) {
+							got = req
+							return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+						},
+					),
+				),
+			)
+
+			_, err := tc.args.req(c.Request()).Post(
+				context.Background(),
+				"http://localhost:8080",
+				tc.args.body,
+			)
+			if tc.want.err {
+				assert.Error(t, err)
+				assert.Nil(t, got)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want.contentType, got.Header.Get(ContentType))
+			assert.Equal(t, tc.want.contentLength, got.ContentLength)
+
+			assert.NotNil(t, got.GetBody)
+
+			for range 2 {
+				body, err := got.GetBody()
+				assert.NoError(t, err)
+
+				data, err := io.ReadAll(body)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want.body, string(data))
+			}
+
+		})
+	}
+
+}
+
+func TestRequest_DoMethods(t *testing.T) {
+	type args struct {
+		send func(Request) (*Response, error)
+	}
+
+	type want struct {
+		method string
+		body   string
+	}
+
+	type test struct {
+		name string
+		args args
+		want want
+	}
+
+	tests := []test{
+		{
+			name: "Custom method",
+			args: args{
+				send: func(req Request) (*Response, error) {
+					return req.Do(context.Background(), "PROPFIND", "http://localhost:8080", bytes.NewReader([]byte(`<propfind/>`)))
+				},
+			},
+			want: want{
+				method: "PROPFIND",
+				body:   `<propfind/>`,
+			},
+		},
+		{
+			name: "Patch with body",
+			args: args{
+				send: func(req Request) (*Response, error) {
+					return req.Patch(context.Background(), "http://localhost:8080", bytes.NewReader([]byte(`[{"op":"remove"}]`)))
+				},
+			},
+			want: want{
+				method: http.MethodPatch,
+				body:   `[{"op":"remove"}]`,
+			},
+		},
+		{
+			name: "Delete with body",
+			args: args{
+				send: func(req Request) (*Response, error) {
+					return req.Delete(context.Background(), "http://localhost:8080", bytes.NewReader([]byte(`{"ids":[1]}`)))
+				},
+			},
+			want: want{
+				method: http.MethodDelete,
+				body:   `{"ids":[1]}`,
+			},
+		},
+		{
+			name: "Options with body",
+			args: args{
+				send: func(req Request) (*Response, error) {
+					return req.Options(context.Background(), "http://localhost:8080", bytes.NewReader([]byte(`{"origin":"example.com"}`)))
+				},
+			},
+			want: want{
+				method: http.MethodOptions,
+				body:   `{"origin":"example.com"}`,
+			},
+		},
+		{
+			name: "Options with typed body",
+			args: args{
+				send: func(req Request) (*Response, error) {
+					return req.WithJSONBody(map[string]int{"id": 1}).Options(context.Background(), "http://localhost:8080", nil)
+				},
+			},
+			want: want{
+				method: http.MethodOptions,
+				body:   `{"id":1}`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var method, body string
+
+			c := NewClient(
+				WithTransport(
+					RoundTripper(
+						func(req *http.Request) (*http.Response, error) {
+							data, err := io.ReadAll(req.Body)
+							if err != nil {
+								return nil, err
+							}
+
+							method, body = req.Method, string(data)
+
+							return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+						},
+					),
+				),
+			)
+
+			_, err := tc.args.send(c.Request())
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want.method, method)
+			assert.Equal(t, tc.want.body, body)
+
+		})
+	}
+
+}
+
+func TestRequest_Clone(t *testing.T) {
+	c := &client{
+		httpClient: http.DefaultClient,
+	}
+
+	original := c.Request().
+		WithHeader("X-Key", "1").
+		WithQuery("page", "1").
+		WithPathParam("id", "1").
+		WithFormField("title", "first").
+		WithTimeout(time.Second)
+
+	clone := original.Clone().
+		WithHeader("X-Key", "2").
+		WithQuery("page", "2").
+		WithPathParam("id", "2").
+		WithFormField("title", "second")
+
+	originalReq := original.(*request)
+	cloneReq := clone.(*request)
+
+	assert.Equal(t, http.Header{"X-Key": {"1"}}, originalReq.header)
+	assert.Equal(t, url.Values{"page": {"1"}}, originalReq.query)
+	assert.Equal(t, map[string]string{"id": "1"}, originalReq.pathParams)
+	assert.Len(t, originalReq.multipart.parts, 1)
+
+	assert.Equal(t, http.Header{"X-Key": {"1", "2"}}, cloneReq.header)
+	assert.Equal(t, url.Values{"page": {"1", "2"}}, cloneReq.query)
+	assert.Equal(t, map[string]string{"id": "2"}, cloneReq.pathParams)
+	assert.Len(t, cloneReq.multipart.parts, 2)
+	assert.Equal(t, originalReq.multipart.boundary, cloneReq.multipart.boundary)
+	assert.Equal(t, time.Second, cloneReq.timeout)
+	assert.Same(t, originalReq.client, cloneReq.client)
+
+}
+
+func TestRequest_ConcurrentReuse(t *testing.T) {
+	c := NewClient(
+		WithTransport(
+			RoundTripper(
+				func(req *http.Request) (*http.Response, error) {
+					req.Header.Set("X-Attempt", req.URL.Query().Get("n"))
+
+					return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+				},
+			),
+		),
+	)
+
+	tmpl := c.Template(
+		func(req Request) Request {
+			return req.WithBearerAuth("token")
+		},
+		func(req Request) Request {
+			return req.WithJSONBody(map[string]int{"id": 1})
+		},
+	)
+
+	shared := c.Request().WithHeader("X-Shared", "true")
+
+	var wg sync.WaitGroup
+
+	for i := range 20 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			n := strconv.Itoa(i)
+
+			req := tmpl.Request().WithQuery("n", n)
+			res, err := req.Post(context.Background(), "http://localhost:8080/{id}", nil)
+			if assert.NoError(t, err) {
+				_ = res.Body.Close()
+
+				assert.Equal(t, "Bearer token", res.Request.Header.Get(Authorization))
+				assert.Equal(t, n, res.Request.Header.Get("X-Attempt"))
+				assert.Equal(t, "n="+n, res.Request.URL.RawQuery)
+			}
+
+			assert.Empty(t, req.Header().Get("X-Attempt"))
+			assert.Equal(t, "n="+n, req.URL().RawQuery)
+
+			res, err = shared.WithQuery("n", n).Get(context.Background(), "http://localhost:8080")
+			if assert.NoError(t, err) {
+				_ = res.Body.Close()
+			}
+
+			_ = shared.Header()
+			_ = shared.URL()
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Empty(t, tmpl.Request().Header().Get("X-Attempt"))
+	assert.Equal(t, "true", shared.Header().Get("X-Shared"))
+	assert.Empty(t, shared.(*request).header.Get("X-Attempt"))
+
+}
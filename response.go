@@ -1,14 +1,34 @@
 package request
 
 import (
+	"context"
 	"encoding/json"
 	"encoding/xml"
+	"io"
 	"net/http"
 	"strings"
 )
 
 type Response struct {
 	*http.Response
+
+	attempts *attemptsRecorder
+}
+
+// Attempts returns attempts made to get response. Attempts are recorded
+// by Retry interceptor, without it response is got by single attempt.
+func (res *Response) Attempts() Attempts {
+	attempts := res.attempts.get()
+	if attempts.Count == 0 {
+		return Attempts{
+			Count:       1,
+			Errors:      []error{nil},
+			StatusCodes: []int{res.StatusCode},
+		}
+	}
+
+	return attempts
+
 }
 
 // IsSuccess checks response status code for success.
@@ -36,3 +56,53 @@ func (res *Response) JSONDecoder() Decoder {
 func (res *Response) XMLDecoder() Decoder {
 	return xml.NewDecoder(res.Body)
 }
+
+// cancelBody cancels request context on close.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+
+	return err
+
+}
+
+// cancelReadWriteBody cancels request context on close
+// and keeps body writable for 101 Switching Protocols responses.
+type cancelReadWriteBody struct {
+	io.ReadWriteCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelReadWriteBody) Close() error {
+	err := b.ReadWriteCloser.Close()
+	b.cancel()
+
+	return err
+
+}
+
+// cancelOnClose wraps response body to cancel request context on close.
+func cancelOnClose(body io.ReadCloser, cancel context.CancelFunc) io.ReadCloser {
+	if body == nil {
+		cancel()
+		return nil
+	}
+
+	if rwc, ok := body.(io.ReadWriteCloser); ok {
+		return &cancelReadWriteBody{
+			ReadWriteCloser: rwc,
+			cancel:          cancel,
+		}
+	}
+
+	return &cancelBody{
+		ReadCloser: body,
+		cancel:     cancel,
+	}
+
+}
@@ -0,0 +1,143 @@
+package request
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestResponse_SlowBody(t *testing.T) {
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(ContentType, ApplicationJSON)
+
+				chunks := []string{`{"id":1,`, `"title":"slow",`, `"completed":true}`}
+				for _, chunk := range chunks {
+					_, _ = w.Write([]byte(chunk))
+					w.(http.Flusher).Flush()
+
+					time.Sleep(50 * time.Millisecond)
+				}
+			},
+		),
+	)
+	defer server.Close()
+
+	type todo struct {
+		ID        int    `json:"id"`
+		Title     string `json:"title"`
+		Completed bool   `json:"completed"`
+	}
+
+	type args struct {
+		timeout time.Duration
+	}
+
+	type want struct {
+		todo todo
+		err  error
+	}
+
+	type test struct {
+		name string
+		args args
+		want want
+	}
+
+	tests := []test{
+		{
+			name: "Body read after send",
+			args: args{
+				timeout: 5 * time.Second,
+			},
+			want: want{
+				todo: todo{ID: 1, Title: "slow", Completed: true},
+			},
+		},
+		{
+			name: "Timeout covers body read",
+			args: args{
+				timeout: 75 * time.Millisecond,
+			},
+			want: want{
+				err: context.DeadlineExceeded,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := NewClient(WithTimeout(tc.args.timeout)).
+				Request().
+				Get(context.Background(), server.URL)
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			var got todo
+			err = res.Decoder().Decode(&got)
+
+			assert.ErrorIs(t, err, tc.want.err)
+			if tc.want.err == nil {
+				assert.Equal(t, tc.want.todo, got)
+			}
+
+		})
+	}
+
+}
+
+func TestResponse_BodyCloseCancelsContext(t *testing.T) {
+	var ctx context.Context
+
+	res, err := NewClient(
+		WithTransport(
+			RoundTripper(
+				func(req *http.Request) (*http.Response, error) {
+					ctx = req.Context()
+
+					return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+				},
+			),
+		),
+	).Request().Get(context.Background(), "http://localhost:8080")
+	require.NoError(t, err)
+
+	_, err = io.ReadAll(res.Body)
+
+	assert.NoError(t, err)
+	assert.NoError(t, ctx.Err())
+
+	assert.NoError(t, res.Body.Close())
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+
+}
+
+func TestResponse_Attempts(t *testing.T) {
+	res, err := NewClient(
+		WithTransport(
+			RoundTripper(
+				func(req *http.Request) (*http.Response, error) {
+					return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+				},
+			),
+		),
+	).Request().Get(context.Background(), "http://localhost:8080")
+	require.NoError(t, err)
+
+	assert.Equal(
+		t,
+		Attempts{
+			Count:       1,
+			Errors:      []error{nil},
+			StatusCodes: []int{http.StatusOK},
+		},
+		res.Attempts(),
+	)
+
+}
@@ -0,0 +1,318 @@
+package request
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	socks5Version = 0x05
+
+	socks5AuthNone         = 0x00
+	socks5AuthPassword     = 0x02
+	socks5AuthNoAcceptable = 0xff
+
+	socks5PasswordVersion = 0x01
+
+	socks5CommandConnect = 0x01
+
+	socks5AddressIPv4   = 0x01
+	socks5AddressDomain = 0x03
+	socks5AddressIPv6   = 0x04
+)
+
+var (
+	ErrSOCKS5Auth = errors.New("socks5: authentication failed")
+)
+
+var socks5Replies = map[byte]string{
+	0x01: "general SOCKS server failure",
+	0x02: "connection not allowed by ruleset",
+	0x03: "network unreachable",
+	0x04: "host unreachable",
+	0x05: "connection refused",
+	0x06: "TTL expired",
+	0x07: "command not supported",
+	0x08: "address type not supported",
+}
+
+// socks5Dialer dials target address through SOCKS5 proxy (RFC 1928)
+// with optional username/password authentication (RFC 1929).
+type socks5Dialer struct {
+	proxyAddress string
+	username     string
+	password     string
+	remoteDNS    bool
+	dial         func(ctx context.Context, network, address string) (net.Conn, error)
+	lookup       func(ctx context.Context, address string) ([]net.IP, error)
+}
+
+// newSOCKS5Dialer creates dialer from socks5:// or socks5h:// proxy URL.
+// With socks5h scheme host names are resolved by proxy, otherwise target
+// "host:port" address is resolved locally with lookup.
+func newSOCKS5Dialer(
+	proxyURL *url.URL,
+	dial func(ctx context.Context, network, address string) (net.Conn, error),
+	lookup func(ctx context.Context, address string) ([]net.IP, error),
+) *socks5Dialer {
+	dialer := &socks5Dialer{
+		proxyAddress: canonicalAddress(proxyURL, "1080"),
+		remoteDNS:    proxyURL.Scheme == "socks5h",
+		dial:         dial,
+		lookup:       lookup,
+	}
+
+	if proxyURL.User != nil {
+		dialer.username = proxyURL.User.Username()
+		dialer.password, _ = proxyURL.User.Password()
+	}
+
+	if dialer.dial == nil {
+		dialer.dial = (&net.Dialer{}).DialContext
+	}
+
+	if dialer.lookup == nil {
+		dialer.lookup = func(ctx context.Context, address string) ([]net.IP, error) {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return nil, err
+			}
+
+			return net.DefaultResolver.LookupIP(ctx, "ip", host)
+		}
+	}
+
+	return dialer
+
+}
+
+// DialContext connects to proxy and asks it to connect to address.
+func (d *socks5Dialer) DialContext(
+	ctx context.Context,
+	network string,
+	address string,
+) (net.Conn, error) {
+	conn, err := d.dial(ctx, network, d.proxyAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.SetDeadline(time.Unix(1, 0))
+		case <-done:
+		}
+	}()
+
+	if err = d.handshake(ctx, conn, address); err != nil {
+		_ = conn.Close()
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		return nil, err
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+
+	return conn, nil
+
+}
+
+// handshake negotiates authentication and sends CONNECT command.
+func (d *socks5Dialer) handshake(
+	ctx context.Context,
+	conn net.Conn,
+	address string,
+) error {
+	methods := []byte{socks5AuthNone}
+	if d.username != "" || d.password != "" {
+		methods = []byte{socks5AuthNone, socks5AuthPassword}
+	}
+
+	greeting := append([]byte{socks5Version, byte(len(methods))}, methods...)
+	if _, err := conn.Write(greeting); err != nil {
+		return err
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+
+	if reply[0] != socks5Version {
+		return fmt.Errorf("socks5: unexpected protocol version %d", reply[0])
+	}
+
+	switch reply[1] {
+	case socks5AuthNone:
+	case socks5AuthPassword:
+		if err := d.authenticate(conn); err != nil {
+			return err
+		}
+	case socks5AuthNoAcceptable:
+		return ErrSOCKS5Auth
+	default:
+		return fmt.Errorf("socks5: unsupported authentication method %d", reply[1])
+	}
+
+	request, err := d.connectRequest(ctx, address)
+	if err != nil {
+		return err
+	}
+
+	if _, err = conn.Write(request); err != nil {
+		return err
+	}
+
+	return readSOCKS5Reply(conn)
+
+}
+
+// authenticate does username/password sub-negotiation.
+func (d *socks5Dialer) authenticate(conn net.Conn) error {
+	if len(d.username) > 255 || len(d.password) > 255 {
+		return fmt.Errorf("socks5: username or password is too long")
+	}
+
+	request := []byte{socks5PasswordVersion, byte(len(d.username))}
+	request = append(request, d.username...)
+	request = append(request, byte(len(d.password)))
+	request = append(request, d.password...)
+
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+
+	if reply[1] != 0x00 {
+		return ErrSOCKS5Auth
+	}
+
+	return nil
+
+}
+
+// connectRequest builds CONNECT command for given address.
+func (d *socks5Dialer) connectRequest(
+	ctx context.Context,
+	address string,
+) ([]byte, error) {
+	host, portString, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("socks5: invalid port %q", portString)
+	}
+
+	request := []byte{socks5Version, socks5CommandConnect, 0x00}
+
+	ip := net.ParseIP(host)
+	if ip == nil && !d.remoteDNS {
+		addresses, err := d.lookup(ctx, address)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(addresses) == 0 {
+			return nil, fmt.Errorf("socks5: no addresses found for %q", host)
+		}
+
+		ip = addresses[0]
+	}
+
+	switch {
+	case ip == nil:
+		if len(host) > 255 {
+			return nil, fmt.Errorf("socks5: host name %q is too long", host)
+		}
+
+		request = append(request, socks5AddressDomain, byte(len(host)))
+		request = append(request, host...)
+	case ip.To4() != nil:
+		request = append(request, socks5AddressIPv4)
+		request = append(request, ip.To4()...)
+	default:
+		request = append(request, socks5AddressIPv6)
+		request = append(request, ip.To16()...)
+	}
+
+	return binary.BigEndian.AppendUint16(request, uint16(port)), nil
+
+}
+
+// readSOCKS5Reply reads CONNECT command reply and bound address.
+func readSOCKS5Reply(conn net.Conn) error {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+
+	if header[0] != socks5Version {
+		return fmt.Errorf("socks5: unexpected protocol version %d", header[0])
+	}
+
+	if header[1] != 0x00 {
+		if message, ok := socks5Replies[header[1]]; ok {
+			return fmt.Errorf("socks5: %s", message)
+		}
+
+		return fmt.Errorf("socks5: unknown reply code %d", header[1])
+	}
+
+	var length int
+	switch header[3] {
+	case socks5AddressIPv4:
+		length = net.IPv4len
+	case socks5AddressIPv6:
+		length = net.IPv6len
+	case socks5AddressDomain:
+		size := make([]byte, 1)
+		if _, err := io.ReadFull(conn, size); err != nil {
+			return err
+		}
+
+		length = int(size[0])
+	default:
+		return fmt.Errorf("socks5: unknown address type %d", header[3])
+	}
+
+	_, err := io.ReadFull(conn, make([]byte, length+2))
+
+	return err
+
+}
+
+// canonicalAddress returns URL host with port, using defaultPort if missing.
+func canonicalAddress(u *url.URL, defaultPort string) string {
+	port := u.Port()
+	if port == "" {
+		port = defaultPort
+	}
+
+	return net.JoinHostPort(u.Hostname(), port)
+
+}
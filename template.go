@@ -0,0 +1,32 @@
+package request
+
+// Template yields pre-configured requests. It is safe for concurrent use.
+type Template interface {
+	Request() Request
+}
+
+type requestTemplate struct {
+	req Request
+}
+
+// Template returns Template yielding requests configured by given
+// functions, e.g. with common HEADER, query or path parameters.
+// Each yielded request is independent clone, so it can be changed freely.
+func (c *client) Template(
+	configure ...func(Request) Request,
+) Template {
+	req := c.Request()
+	for _, fn := range configure {
+		req = fn(req)
+	}
+
+	return &requestTemplate{
+		req: req.Clone(),
+	}
+
+}
+
+// Request returns new request clone of template.
+func (t *requestTemplate) Request() Request {
+	return t.req.Clone()
+}
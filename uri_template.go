@@ -0,0 +1,212 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidURITemplate = errors.New("invalid URI template")
+)
+
+// uriTemplateOperator describes RFC 6570 expression operator behaviour.
+type uriTemplateOperator struct {
+	first    string
+	sep      string
+	named    bool
+	ifEmpty  string
+	reserved bool
+}
+
+var uriTemplateOperators = map[byte]uriTemplateOperator{
+	'+': {first: "", sep: ",", reserved: true},
+	'#': {first: "#", sep: ",", reserved: true},
+	'.': {first: ".", sep: "."},
+	'/': {first: "/", sep: "/"},
+	';': {first: ";", sep: ";", named: true},
+	'?': {first: "?", sep: "&", named: true, ifEmpty: "="},
+	'&': {first: "&", sep: "&", named: true, ifEmpty: "="},
+}
+
+// expandURITemplate expands RFC 6570 URI template up to level 3
+// with given variables, prefix modifier from level 4 is supported too.
+// Undefined variables are omitted.
+func expandURITemplate(template string, vars map[string]string) (string, error) {
+	var builder strings.Builder
+
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			if strings.IndexByte(template, '}') >= 0 {
+				return "", fmt.Errorf("%w: unexpected '}'", ErrInvalidURITemplate)
+			}
+
+			builder.WriteString(template)
+
+			return builder.String(), nil
+		}
+
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("%w: unclosed expression", ErrInvalidURITemplate)
+		}
+
+		builder.WriteString(template[:start])
+
+		if err := expandURITemplateExpression(
+			&builder,
+			template[start+1:start+end],
+			vars,
+		); err != nil {
+			return "", err
+		}
+
+		template = template[start+end+1:]
+	}
+
+}
+
+// expandURITemplateExpression expands single expression without braces.
+func expandURITemplateExpression(
+	builder *strings.Builder,
+	expression string,
+	vars map[string]string,
+) error {
+	if expression == "" {
+		return fmt.Errorf("%w: empty expression", ErrInvalidURITemplate)
+	}
+
+	operator, ok := uriTemplateOperators[expression[0]]
+	if ok {
+		expression = expression[1:]
+	} else {
+		operator = uriTemplateOperator{sep: ","}
+	}
+
+	first := true
+
+	for _, spec := range strings.Split(expression, ",") {
+		name, maxLength, err := parseURITemplateVarSpec(spec)
+		if err != nil {
+			return err
+		}
+
+		value, ok := vars[name]
+		if !ok {
+			continue
+		}
+
+		if first {
+			builder.WriteString(operator.first)
+			first = false
+		} else {
+			builder.WriteString(operator.sep)
+		}
+
+		if maxLength > 0 && utf8.RuneCountInString(value) > maxLength {
+			value = string([]rune(value)[:maxLength])
+		}
+
+		if operator.named {
+			builder.WriteString(name)
+
+			if value == "" {
+				builder.WriteString(operator.ifEmpty)
+				continue
+			}
+
+			builder.WriteByte('=')
+		}
+
+		builder.WriteString(escapeURITemplateValue(value, operator.reserved))
+	}
+
+	return nil
+
+}
+
+// parseURITemplateVarSpec parses variable name and optional prefix length.
+// Explode modifier is accepted and ignored since values are strings.
+func parseURITemplateVarSpec(spec string) (string, int, error) {
+	spec = strings.TrimSuffix(spec, "*")
+
+	name, prefix, hasPrefix := strings.Cut(spec, ":")
+
+	if name == "" {
+		return "", 0, fmt.Errorf("%w: empty variable name", ErrInvalidURITemplate)
+	}
+
+	for i := 0; i < len(name); i++ {
+		if !isURITemplateVarChar(name[i]) {
+			return "", 0, fmt.Errorf("%w: invalid variable name %q", ErrInvalidURITemplate, name)
+		}
+	}
+
+	if !hasPrefix {
+		return name, 0, nil
+	}
+
+	maxLength, err := strconv.Atoi(prefix)
+	if err != nil || maxLength <= 0 || maxLength >= 10000 {
+		return "", 0, fmt.Errorf("%w: invalid prefix %q", ErrInvalidURITemplate, prefix)
+	}
+
+	return name, maxLength, nil
+
+}
+
+func isURITemplateVarChar(c byte) bool {
+	return c >= 'a' && c <= 'z' ||
+		c >= 'A' && c <= 'Z' ||
+		c >= '0' && c <= '9' ||
+		c == '_' || c == '.' || c == '%'
+}
+
+func isUnreserved(c byte) bool {
+	return c >= 'a' && c <= 'z' ||
+		c >= 'A' && c <= 'Z' ||
+		c >= '0' && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func isReserved(c byte) bool {
+	return strings.IndexByte(":/?#[]@!$&'()*+,;=", c) >= 0
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' ||
+		c >= 'a' && c <= 'f' ||
+		c >= 'A' && c <= 'F'
+}
+
+// escapeURITemplateValue percent-encodes value. Reserved characters
+// and existing percent-encoded triplets are kept if reserved is true.
+func escapeURITemplateValue(value string, reserved bool) string {
+	const hex = "0123456789ABCDEF"
+
+	var builder strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+
+		switch {
+		case isUnreserved(c):
+			builder.WriteByte(c)
+		case reserved && isReserved(c):
+			builder.WriteByte(c)
+		case reserved && c == '%' && i+2 < len(value) && isHex(value[i+1]) && isHex(value[i+2]):
+			builder.WriteString(value[i : i+3])
+			i += 2
+		default:
+			builder.WriteByte('%')
+			builder.WriteByte(hex[c>>4])
+			builder.WriteByte(hex[c&0x0f])
+		}
+	}
+
+	return builder.String()
+
+}
@@ -0,0 +1,70 @@
+package request
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestExpandURITemplate(t *testing.T) {
+	vars := map[string]string{
+		"var":   "value",
+		"hello": "Hello World!",
+		"path":  "/foo/bar",
+		"empty": "",
+		"x":     "1024",
+		"y":     "768",
+		"id":    "a/b c",
+		"page":  "2",
+	}
+
+	type args struct {
+		template string
+	}
+
+	type want struct {
+		url string
+		err error
+	}
+
+	type test struct {
+		name string
+		args args
+		want want
+	}
+
+	tests := []test{
+		{name: "Literal", args: args{"/users"}, want: want{url: "/users"}},
+		{name: "Simple", args: args{"{var}"}, want: want{url: "value"}},
+		{name: "Simple escaping", args: args{"{hello}"}, want: want{url: "Hello%20World%21"}},
+		{name: "Reserved", args: args{"{+path}/here"}, want: want{url: "/foo/bar/here"}},
+		{name: "Reserved escaping", args: args{"{+hello}"}, want: want{url: "Hello%20World!"}},
+		{name: "Fragment", args: args{"X{#var}"}, want: want{url: "X#value"}},
+		{name: "Multiple", args: args{"map?{x,y}"}, want: want{url: "map?1024,768"}},
+		{name: "Label", args: args{"X{.x,y}"}, want: want{url: "X.1024.768"}},
+		{name: "Path segments", args: args{"{/var,x}/here"}, want: want{url: "/value/1024/here"}},
+		{name: "Path parameters", args: args{"{;x,y,empty}"}, want: want{url: ";x=1024;y=768;empty"}},
+		{name: "Query", args: args{"{?x,y,empty}"}, want: want{url: "?x=1024&y=768&empty="}},
+		{name: "Query continuation", args: args{"?fixed=yes{&x}"}, want: want{url: "?fixed=yes&x=1024"}},
+		{name: "Prefix", args: args{"{var:3}"}, want: want{url: "val"}},
+		{name: "Undefined", args: args{"/users{/undefined}{?undefined}"}, want: want{url: "/users"}},
+		{
+			name: "Path with query",
+			args: args{"/users/{id}/posts{?page,limit}"},
+			want: want{url: "/users/a%2Fb%20c/posts?page=2"},
+		},
+		{name: "Unclosed", args: args{"/users/{id"}, want: want{err: ErrInvalidURITemplate}},
+		{name: "Unexpected close", args: args{"/users/id}"}, want: want{err: ErrInvalidURITemplate}},
+		{name: "Invalid name", args: args{"/users/{i d}"}, want: want{err: ErrInvalidURITemplate}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			url, err := expandURITemplate(tc.args.template, vars)
+
+			assert.Equal(t, tc.want.url, url)
+			assert.ErrorIs(t, err, tc.want.err)
+
+		})
+	}
+
+}